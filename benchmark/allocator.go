@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+func benchAllocator(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		AllocatorResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	objSize, err := queryInt(r, "obj_size", 64, 1, 64*1024*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 10000000, 1, 1000000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.AllocatorResult = *benchmarkAllocator(objSize, count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type AllocatorResult struct {
+	ObjectSize int
+	Count      int
+	SingleProc AllocatorRun
+	AllProcs   AllocatorRun
+}
+
+type AllocatorRun struct {
+	GOMAXPROCS    int
+	Seconds       float32
+	AllocsPerSec  float64
+	MallocsBefore uint64
+	MallocsAfter  uint64
+}
+
+// allocSinks gives every allocating goroutine somewhere to store its slices
+// so the compiler cannot prove they are unused and drop the allocation.
+var allocSinks [][]byte
+
+// sink is where the other benchmarks publish the results they compute and
+// never use, for the same reason. Each keeps its results in a typed local
+// while timing and publishes the last one with keep afterwards, so nothing
+// is boxed inside the timed loop. Most of them run without the benchmark
+// lock, so the store is atomic.
+var sink atomic.Pointer[any]
+
+func keep(v any) {
+	sink.Store(&v)
+}
+
+// benchmarkAllocator allocates count byte slices of objectSize first with
+// GOMAXPROCS=1 and then with GOMAXPROCS=runtime.NumCPU(), splitting the work
+// over one goroutine per P. GOMAXPROCS is restored afterwards.
+func benchmarkAllocator(objectSize int, count int) *AllocatorResult {
+	prev := runtime.GOMAXPROCS(0)
+	defer runtime.GOMAXPROCS(prev)
+
+	return &AllocatorResult{
+		ObjectSize: objectSize,
+		Count:      count,
+		SingleProc: runAllocator(1, objectSize, count),
+		AllProcs:   runAllocator(runtime.NumCPU(), objectSize, count),
+	}
+}
+
+func runAllocator(procs int, objectSize int, count int) AllocatorRun {
+	runtime.GOMAXPROCS(procs)
+	runtime.GC()
+
+	allocSinks = make([][]byte, procs)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	since := runConcurrently(procs, func(p int) {
+		n := count / procs
+		if p < count%procs {
+			n++
+		}
+		for range n {
+			allocSinks[p] = make([]byte, objectSize)
+		}
+	})
+
+	runtime.ReadMemStats(&after)
+	allocSinks = nil
+
+	return AllocatorRun{
+		GOMAXPROCS:    procs,
+		Seconds:       float32(since) / float32(time.Second),
+		AllocsPerSec:  float64(count) / since.Seconds(),
+		MallocsBefore: before.Mallocs,
+		MallocsAfter:  after.Mallocs,
+	}
+}
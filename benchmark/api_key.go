@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+var apiKey string
+
+// requireAPIKey rejects requests that do not present BM_API_KEY, either as a
+// bearer token or in the X-API-Key header. Endpoints behind it are only
+// registered when BM_API_KEY is set.
+func requireAPIKey(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+			w.WriteHeader(401)
+			return
+		}
+
+		h(w, r)
+	}
+}
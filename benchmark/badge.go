@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+)
+
+// badgeMetric computes a badge value from a disk benchmark result. Totals are
+// taken over all size classes that ran.
+type badgeMetric struct {
+	label            string
+	unit             string
+	defaultThreshold float64
+	value            func(DiskBenchmarkResult) float64
+}
+
+var badgeMetrics = map[string]badgeMetric{
+	"iops": {"iops", "", 1000, func(res DiskBenchmarkResult) float64 {
+		total := diskTotals(res)
+		return total.IOPS()
+	}},
+	"throughput": {"throughput", " MB/s", 100, func(res DiskBenchmarkResult) float64 {
+		total := diskTotals(res)
+		return total.ThroughputMBps()
+	}},
+}
+
+// diskTotals sums the results of every size class that ran.
+func diskTotals(res DiskBenchmarkResult) *DiskResult {
+	total := &DiskResult{}
+	for _, class := range defaultSizeClasses() {
+		if rw := res.get(class.Name); rw != nil {
+			total.Count += rw.Count
+			total.Bytes += rw.Bytes
+			total.Seconds += rw.Seconds
+		}
+	}
+	return total
+}
+
+// badgeBorderline is the fraction of the threshold above which a value that
+// misses the threshold is shown as yellow rather than red.
+const badgeBorderline = 0.8
+
+// benchBadge serves /badge/{disk}/{metric} for the latest stored result of the
+// disk, where disk is ephemeral-disk or persistent-disk. The threshold query
+// parameter overrides the metric's default threshold, and format=json returns
+// a shields.io endpoint payload instead of an SVG.
+func benchBadge(w http.ResponseWriter, r *http.Request) {
+	var disk string
+	switch r.PathValue("disk") {
+	case "ephemeral-disk":
+		disk = "ephemeral"
+	case "persistent-disk":
+		disk = "persistent"
+	default:
+		http.Error(w, "unknown disk "+r.PathValue("disk"), 404)
+		return
+	}
+
+	metric, ok := badgeMetrics[r.PathValue("metric")]
+	if !ok {
+		http.Error(w, "unknown metric "+r.PathValue("metric"), 404)
+		return
+	}
+
+	threshold := metric.defaultThreshold
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		var err error
+		if threshold, err = strconv.ParseFloat(v, 64); err != nil {
+			http.Error(w, "query parameter threshold: "+err.Error(), 400)
+			return
+		}
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "svg" && format != "json" {
+		http.Error(w, fmt.Sprintf("query parameter format: unknown format %q", format), 400)
+		return
+	}
+
+	results, err := resultStore.Read()
+	if err != nil {
+		fmt.Println(requestIDFromContext(r.Context()), err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	latest := latestResult(results, disk)
+	if latest == nil {
+		http.Error(w, "no results for "+disk+" disk", 404)
+		return
+	}
+
+	value := metric.value(latest.Result)
+
+	color := "red"
+	if value >= threshold {
+		color = "green"
+	} else if value >= threshold*badgeBorderline {
+		color = "yellow"
+	}
+
+	label := disk + " " + metric.label
+	message := strconv.FormatFloat(value, 'f', 1, 64) + metric.unit
+
+	if format == "json" {
+		type Response struct {
+			SchemaVersion int    `json:"schemaVersion"`
+			Label         string `json:"label"`
+			Message       string `json:"message"`
+			Color         string `json:"color"`
+		}
+
+		writePrettyJSON(w, 200, Response{1, label, message, color}, queryPretty(r))
+		return
+	}
+
+	w.Header().Add("content-type", "image/svg+xml")
+	w.Header().Add("cache-control", "no-cache")
+	w.Write([]byte(badgeSVG(label, message, color)))
+}
+
+var badgeColors = map[string]string{
+	"green":  "#4c1",
+	"yellow": "#dfb317",
+	"red":    "#e05d44",
+}
+
+// badgeSVG renders a flat shields.io style badge. Text widths are estimated
+// from the character count, which is close enough for short labels.
+func badgeSVG(label, message, color string) string {
+	lw := 10 + 7*len(label)
+	mw := 10 + 7*len(message)
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="14">%[4]s</text><text x="%[8]d" y="14">%[5]s</text></g></svg>`,
+		lw+mw, lw, mw, label, message, badgeColors[color], lw/2, lw+mw/2)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+func benchBigInt(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		BigIntResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	bits, err := queryInt(r, "bits", 2048, 8, 8192)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100, 1, 10000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkBigInt(bits, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.BigIntResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// BigIntResult holds the operations per second of each big.Int operation,
+// keyed by method name.
+type BigIntResult struct {
+	Bits      int
+	Count     int
+	OpsPerSec map[string]float64
+}
+
+// benchmarkBigInt draws three random numbers of exactly bits bits, the
+// modulus made odd as it is for RSA, and runs each operation count times on
+// them. Exp uses a full size exponent, so it dominates the run time.
+func benchmarkBigInt(bits int, count int) (*BigIntResult, error) {
+	var operands [3]*big.Int
+	for i := range operands {
+		n, err := crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))
+		if err != nil {
+			return nil, fmt.Errorf("random operand: %w", err)
+		}
+		operands[i] = n.SetBit(n, bits-1, 1)
+	}
+	x, y, m := operands[0], operands[1], operands[2].SetBit(operands[2], 0, 1)
+
+	z := new(big.Int)
+
+	ops := []struct {
+		name string
+		fn   func()
+	}{
+		{"Add", func() { z.Add(x, y) }},
+		{"Mul", func() { z.Mul(x, y) }},
+		{"Exp", func() { z.Exp(x, y, m) }},
+		{"GCD", func() { z.GCD(nil, nil, x, y) }},
+	}
+
+	res := &BigIntResult{Bits: bits, Count: count, OpsPerSec: map[string]float64{}}
+
+	for _, op := range ops {
+		start := time.Now()
+		for range count {
+			op.fn()
+		}
+		res.OpsPerSec[op.name] = float64(count) / time.Since(start).Seconds()
+	}
+
+	keep(z)
+
+	return res, nil
+}
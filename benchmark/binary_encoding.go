@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func benchBinaryEncoding(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		BinaryEncodingResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	count, err := queryInt(r, "count", 1000000, 1, 1000000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkBinaryEncoding(count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.BinaryEncodingResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type BinaryEncodingResult struct {
+	Count        int
+	MessageSize  int
+	BigEndian    BinaryEncodingRun
+	LittleEndian BinaryEncodingRun
+}
+
+type BinaryEncodingRun struct {
+	EncodeOpsPerSec float64
+	DecodeOpsPerSec float64
+}
+
+// binaryMessage is a fixed size record of the kind binary protocols exchange.
+type binaryMessage struct {
+	ID        int64
+	Value     float64
+	Key       [16]byte
+	Timestamp uint32
+}
+
+// benchmarkBinaryEncoding encodes a binaryMessage count times with
+// binary.Write and decodes it count times with binary.Read, once for each
+// byte order.
+func benchmarkBinaryEncoding(count int) (*BinaryEncodingResult, error) {
+	msg := binaryMessage{ID: 42, Value: 3.14, Timestamp: 1700000000}
+	copy(msg.Key[:], "0123456789abcdef")
+
+	res := &BinaryEncodingResult{Count: count, MessageSize: binary.Size(msg)}
+
+	var err error
+	if res.BigEndian, err = runBinaryEncoding(binary.BigEndian, msg, count); err != nil {
+		return nil, &StepError{"big endian", err}
+	}
+	if res.LittleEndian, err = runBinaryEncoding(binary.LittleEndian, msg, count); err != nil {
+		return nil, &StepError{"little endian", err}
+	}
+
+	return res, nil
+}
+
+func runBinaryEncoding(order binary.ByteOrder, msg binaryMessage, count int) (BinaryEncodingRun, error) {
+	var buf bytes.Buffer
+
+	start := time.Now()
+
+	for range count {
+		buf.Reset()
+		if err := binary.Write(&buf, order, &msg); err != nil {
+			return BinaryEncodingRun{}, fmt.Errorf("encode: %w", err)
+		}
+	}
+
+	encode := time.Since(start)
+
+	encoded := buf.Bytes()
+	rd := bytes.NewReader(encoded)
+	var out binaryMessage
+
+	start = time.Now()
+
+	for range count {
+		rd.Reset(encoded)
+		if err := binary.Read(rd, order, &out); err != nil {
+			return BinaryEncodingRun{}, fmt.Errorf("decode: %w", err)
+		}
+	}
+
+	decode := time.Since(start)
+
+	if out != msg {
+		return BinaryEncodingRun{}, fmt.Errorf("decoded %+v, want %+v", out, msg)
+	}
+
+	return BinaryEncodingRun{
+		EncodeOpsPerSec: float64(count) / encode.Seconds(),
+		DecodeOpsPerSec: float64(count) / decode.Seconds(),
+	}, nil
+}
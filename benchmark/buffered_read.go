@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+func benchBufferedRead(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		BufferedReadResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	fileMB, err := queryInt(r, "file_mb", 256, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	readKB, err := queryInt(r, "read_kb", 4, 1, 16*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkBufferedRead(dir, fileMB*1024*1024, readKB*1024)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.BufferedReadResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type BufferedReadResult struct {
+	FileSize     int
+	ReadSize     int
+	DirectGBps   float64
+	BufferedGBps float64
+}
+
+// bufferedReadSize is the bufio.Reader buffer benchmarkBufferedRead uses.
+const bufferedReadSize = 64 * 1024
+
+// benchmarkBufferedRead writes a file of fileSize and reads it sequentially
+// in readSize chunks twice: with os.File.Read, and through a bufio.Reader of
+// bufferedReadSize. The file is evicted from the page cache before each read,
+// so both start from the disk.
+func benchmarkBufferedRead(dir string, fileSize int, readSize int) (*BufferedReadResult, error) {
+	f, err := createTemp(dir, "buffered_read_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	chunk := make([]byte, 1024*1024)
+	if err := fillContent(chunk); err != nil {
+		return nil, err
+	}
+
+	for written := 0; written < fileSize; {
+		n, err := f.Write(chunk[:min(len(chunk), fileSize-written)])
+		if err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+		written += n
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("sync temp file: %w", err)
+	}
+
+	buf := make([]byte, readSize)
+
+	direct, err := timeChunkedRead(f, buf, func(r io.Reader) io.Reader { return r })
+	if err != nil {
+		return nil, err
+	}
+
+	buffered, err := timeChunkedRead(f, buf, func(r io.Reader) io.Reader { return bufio.NewReaderSize(r, bufferedReadSize) })
+	if err != nil {
+		return nil, err
+	}
+
+	gb := float64(fileSize) / (1 << 30)
+
+	return &BufferedReadResult{
+		FileSize:     fileSize,
+		ReadSize:     readSize,
+		DirectGBps:   gb / direct.Seconds(),
+		BufferedGBps: gb / buffered.Seconds(),
+	}, nil
+}
+
+// timeChunkedRead evicts f from the page cache and reads it from the start
+// through wrap(f), len(buf) bytes per call, returning how long it took.
+func timeChunkedRead(f *os.File, buf []byte, wrap func(io.Reader) io.Reader) (time.Duration, error) {
+	if err := evictPageCache(f); err != nil {
+		return 0, fmt.Errorf("evict temp file from page cache: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seek temp file: %w", err)
+	}
+
+	start := time.Now()
+
+	r := wrap(f)
+	for {
+		_, err := r.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read temp file: %w", err)
+		}
+	}
+
+	return time.Since(start), nil
+}
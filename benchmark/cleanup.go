@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// tempFiles tracks every file and directory the benchmarks have created and
+// not yet removed, so they can be deleted if the process is stopped mid-run.
+var tempFiles = &cleanupRegistry{paths: map[string]struct{}{}}
+
+type cleanupRegistry struct {
+	mu    sync.Mutex
+	paths map[string]struct{}
+}
+
+func (c *cleanupRegistry) add(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths[path] = struct{}{}
+}
+
+func (c *cleanupRegistry) remove(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.paths, path)
+}
+
+// cleanup deletes every registered path and returns how many were removed.
+func (c *cleanupRegistry) cleanup() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for path := range c.paths {
+		if err := os.RemoveAll(path); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		delete(c.paths, path)
+		removed++
+	}
+	return removed
+}
+
+// createTemp is os.CreateTemp for benchmark artifacts. Files created with it
+// must be deleted with removeTemp.
+func createTemp(dir, pattern string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err == nil {
+		tempFiles.add(f.Name())
+	}
+	return f, err
+}
+
+// mkdirTemp is os.MkdirTemp for benchmark artifacts. Directories created with
+// it must be deleted with removeTemp.
+func mkdirTemp(dir, pattern string) (string, error) {
+	name, err := os.MkdirTemp(dir, pattern)
+	if err == nil {
+		tempFiles.add(name)
+	}
+	return name, err
+}
+
+// removeTemp deletes a file or directory tree created by createTemp or
+// mkdirTemp.
+func removeTemp(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	tempFiles.remove(path)
+	return nil
+}
+
+// keepTemp stops tracking path without deleting it.
+func keepTemp(path string) {
+	tempFiles.remove(path)
+}
+
+// cleanupOnSignal deletes all registered benchmark artifacts and exits when
+// the process receives SIGTERM or SIGINT.
+func cleanupOnSignal() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		sig := <-ch
+		log.Printf("received %s, cleaned up %d benchmark files", sig, tempFiles.cleanup())
+		os.Exit(1)
+	}()
+}
+
+// cleanupHandler deletes copy-benchmark files left behind in the benchmark
+// directories, for instance by a previous process that was killed. It runs
+// under exclusive so it cannot remove files of a benchmark in progress.
+func cleanupHandler(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		Removed int
+	}
+
+	var response Response
+
+	for _, dir := range []string{ephemeralDir, persistentDir} {
+		paths, err := filepath.Glob(filepath.Join(dir, "small_file_*"))
+		if err != nil {
+			fmt.Println(requestIDFromContext(r.Context()), err)
+			writeJSONError(w, 500, err, "")
+			return
+		}
+
+		for _, path := range paths {
+			if err := removeTemp(path); err != nil {
+				fmt.Println(requestIDFromContext(r.Context()), err)
+				writeJSONError(w, 500, err, "")
+				return
+			}
+			response.Removed++
+		}
+	}
+
+	log.Printf("cleanup removed %d leftover benchmark files", response.Removed)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
@@ -0,0 +1,10 @@
+package main
+
+// CloudMeta describes where the benchmark is running. The operator supplies
+// it through BM_INSTANCE_TYPE and BM_REGION or the config file.
+type CloudMeta struct {
+	InstanceType string
+	Region       string
+}
+
+var cloudMeta CloudMeta
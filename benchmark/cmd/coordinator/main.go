@@ -0,0 +1,264 @@
+// Command coordinator runs the same benchmarks on several remote agents in
+// parallel and combines their results into a single JSON report.
+//
+// It reads a YAML config such as
+//
+//	agents:
+//	  - host: 10.0.1.12:5555
+//	  - host: 10.8.4.3:5555
+//	endpoints:
+//	  - path: /ephemeral-disk
+//	  - path: /persistent-disk
+//	    body: '{"classes":{"huge":{"count":0}}}'
+//	timeout: 1h
+//
+// Endpoints with a body are sent as POST, the rest as GET. When endpoints is
+// omitted the two disk benchmarks are run with their defaults. The report
+// keys results by agent host and then by endpoint path, so hosts and paths
+// must each be unique. With -pdf the report is also rendered as a PDF, see
+// pkg/report.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"benchmark/pkg/report"
+
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Agents    []AgentConfig    `yaml:"agents"`
+	Endpoints []EndpointConfig `yaml:"endpoints"`
+	Timeout   time.Duration    `yaml:"timeout"`
+}
+
+type AgentConfig struct {
+	Host string `yaml:"host"`
+}
+
+type EndpointConfig struct {
+	Path string `yaml:"path"`
+	Body string `yaml:"body"`
+}
+
+var defaultEndpoints = []EndpointConfig{
+	{Path: "/ephemeral-disk"},
+	{Path: "/persistent-disk"},
+}
+
+// CloudMeta mirrors the agent's CloudMeta as served by /meta.
+type CloudMeta = report.CloudMeta
+
+// AggregatedResult is everything collected from one agent. It lives in
+// pkg/report so the PDF report can be generated from it.
+type AggregatedResult = report.AggregatedResult
+
+type AggregatedReport struct {
+	GeneratedAt time.Time
+	Results     []AggregatedResult
+}
+
+func main() {
+	configPath := flag.String("config", "", "path to the YAML config file")
+	outPath := flag.String("o", "", "write the report to this file instead of stdout")
+	pdfPath := flag.String("pdf", "", "also write the report as a PDF to this file")
+	flag.Parse()
+
+	if *configPath == "" {
+		log.Fatalln("-config is required")
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	res := run(context.Background(), cfg)
+
+	if *pdfPath != "" {
+		if err := writePDF(*pdfPath, res); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	b = append(b, '\n')
+
+	if *outPath == "" {
+		os.Stdout.Write(b)
+	} else if err := os.WriteFile(*outPath, b, 0o644); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func writePDF(path string, res AggregatedReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create pdf: %w", err)
+	}
+
+	gen := report.ReportGenerator{GeneratedAt: res.GeneratedAt}
+	if err := gen.Generate(f, res.Results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func loadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	if len(cfg.Agents) == 0 {
+		return nil, fmt.Errorf("config: no agents")
+	}
+	hosts := map[string]bool{}
+	for i, agent := range cfg.Agents {
+		if agent.Host == "" {
+			return nil, fmt.Errorf("config: agents[%d]: host is required", i)
+		}
+		if hosts[agent.Host] {
+			return nil, fmt.Errorf("config: agents[%d]: duplicate host %s", i, agent.Host)
+		}
+		hosts[agent.Host] = true
+	}
+	if len(cfg.Endpoints) == 0 {
+		cfg.Endpoints = defaultEndpoints
+	}
+	paths := map[string]bool{}
+	for i, endpoint := range cfg.Endpoints {
+		if paths[endpoint.Path] {
+			return nil, fmt.Errorf("config: endpoints[%d]: duplicate path %s", i, endpoint.Path)
+		}
+		paths[endpoint.Path] = true
+	}
+
+	return &cfg, nil
+}
+
+// run queries every agent in parallel. Within an agent the endpoints run one
+// after the other, since the agent only runs one benchmark at a time. Failures
+// are recorded in the report rather than aborting the run.
+func run(ctx context.Context, cfg *Config) AggregatedReport {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	results := make([]AggregatedResult, len(cfg.Agents))
+
+	var g errgroup.Group
+
+	for i, agent := range cfg.Agents {
+		g.Go(func() error {
+			results[i] = runAgent(ctx, client, agent, cfg.Endpoints)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return AggregatedReport{
+		GeneratedAt: time.Now().UTC(),
+		Results:     results,
+	}
+}
+
+func runAgent(ctx context.Context, client *http.Client, agent AgentConfig, endpoints []EndpointConfig) AggregatedResult {
+	res := AggregatedResult{
+		Agent:   agent.Host,
+		Results: map[string]json.RawMessage{},
+		Errors:  map[string]string{},
+	}
+
+	fail := func(key string, err error) {
+		res.Errors[key] = err.Error()
+	}
+
+	type Meta struct {
+		Hostname  string
+		CloudMeta CloudMeta
+	}
+
+	if b, err := call(ctx, client, agent.Host, EndpointConfig{Path: "/meta"}); err != nil {
+		fail("/meta", err)
+	} else {
+		var meta Meta
+		if err := json.Unmarshal(b, &meta); err != nil {
+			fail("/meta", err)
+		}
+		res.Hostname = meta.Hostname
+		res.CloudMeta = meta.CloudMeta
+	}
+
+	for _, endpoint := range endpoints {
+		b, err := call(ctx, client, agent.Host, endpoint)
+		if err != nil {
+			fail(endpoint.Path, err)
+			continue
+		}
+		if !json.Valid(b) {
+			fail(endpoint.Path, fmt.Errorf("response is not valid JSON"))
+			continue
+		}
+		res.Results[endpoint.Path] = b
+	}
+
+	return res
+}
+
+func call(ctx context.Context, client *http.Client, host string, endpoint EndpointConfig) ([]byte, error) {
+	url := host + endpoint.Path
+	if !strings.Contains(host, "://") {
+		url = "http://" + url
+	}
+
+	method := http.MethodGet
+	var body io.Reader
+	if endpoint.Body != "" {
+		method = http.MethodPost
+		body = bytes.NewBufferString(endpoint.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, endpoint.Path, resp.Status, bytes.TrimSpace(b))
+	}
+
+	return b, nil
+}
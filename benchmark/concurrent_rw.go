@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func benchConcurrentRW(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		ConcurrentRWResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	readers, err := queryInt(r, "readers", 4, 1, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	writers, err := queryInt(r, "writers", 4, 1, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	durationS, err := queryInt(r, "duration_s", 5, 1, 300)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkConcurrentReadWrite(dir, readers, writers, time.Duration(durationS)*time.Second)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.ConcurrentRWResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// ConcurrentRWResult has the IOPS of the readers and the writers running
+// alone and together. ContentionRatio is the larger of the two alone divided
+// by the combined IOPS of both together: near 1 or above, readers and writers
+// are serialized against each other; well below 1, they run in parallel.
+type ConcurrentRWResult struct {
+	Readers         int
+	Writers         int
+	DurationSeconds float64
+	ReadIOPSAlone   float64
+	WriteIOPSAlone  float64
+	ReadIOPS        float64
+	WriteIOPS       float64
+	CombinedIOPS    float64
+	ContentionRatio float64
+}
+
+const (
+	concurrentRWFileSize  = 64 * 1024 * 1024
+	concurrentRWBlockSize = 4096
+)
+
+// benchmarkConcurrentReadWrite has readers goroutines read and writers
+// goroutines overwrite random blocks of one shared file in dir for duration,
+// first each group alone and then both at once, so a run takes three times
+// duration.
+func benchmarkConcurrentReadWrite(dir string, readers int, writers int, duration time.Duration) (*ConcurrentRWResult, error) {
+	f, err := createTemp(dir, "concurrent_rw_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	buf := make([]byte, 1024*1024)
+	if err := fillContent(buf); err != nil {
+		return nil, err
+	}
+	for written := 0; written < concurrentRWFileSize; written += len(buf) {
+		if _, err := f.Write(buf); err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+	}
+
+	res := &ConcurrentRWResult{Readers: readers, Writers: writers, DurationSeconds: duration.Seconds()}
+
+	reads, _, err := runReadWrite(f, readers, 0, duration)
+	if err != nil {
+		return nil, &StepError{"read", err}
+	}
+	res.ReadIOPSAlone = reads
+
+	_, writes, err := runReadWrite(f, 0, writers, duration)
+	if err != nil {
+		return nil, &StepError{"write", err}
+	}
+	res.WriteIOPSAlone = writes
+
+	if res.ReadIOPS, res.WriteIOPS, err = runReadWrite(f, readers, writers, duration); err != nil {
+		return nil, &StepError{"combined", err}
+	}
+	res.CombinedIOPS = res.ReadIOPS + res.WriteIOPS
+	res.ContentionRatio = max(res.ReadIOPSAlone, res.WriteIOPSAlone) / res.CombinedIOPS
+
+	return res, nil
+}
+
+// runReadWrite runs readers and writers goroutines on random blocks of f
+// for duration and returns the read and write IOPS.
+func runReadWrite(f *os.File, readers int, writers int, duration time.Duration) (float64, float64, error) {
+	var reads, writes atomic.Int64
+	blocks := int64(concurrentRWFileSize / concurrentRWBlockSize)
+
+	var g errgroup.Group
+
+	start := time.Now()
+
+	for i := range readers + writers {
+		write := i >= readers
+		g.Go(func() error {
+			block := make([]byte, concurrentRWBlockSize)
+			if err := fillContent(block); err != nil {
+				return err
+			}
+
+			for time.Since(start) < duration {
+				off := rand.Int64N(blocks) * concurrentRWBlockSize
+				if write {
+					if _, err := f.WriteAt(block, off); err != nil {
+						return fmt.Errorf("write to temp file: %w", err)
+					}
+					writes.Add(1)
+				} else {
+					if _, err := f.ReadAt(block, off); err != nil {
+						return fmt.Errorf("read temp file: %w", err)
+					}
+					reads.Add(1)
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return 0, 0, err
+	}
+
+	since := time.Since(start).Seconds()
+
+	return float64(reads.Load()) / since, float64(writes.Load()) / since, nil
+}
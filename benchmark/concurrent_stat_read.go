@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func benchConcurrentStatRead(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		ConcurrentStatReadResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	files, err := queryInt(r, "files", 1000, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	workers, err := queryInt(r, "workers", 8, 1, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkConcurrentStatRead(dir, files, workers)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.ConcurrentStatReadResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type ConcurrentStatReadResult struct {
+	Files          int
+	Workers        int
+	Ops            int64
+	ThroughputMBps float64
+	StatLatency    LatencyStats
+}
+
+const (
+	statReadDuration = 5 * time.Second
+	statReadMinSize  = 1024
+	statReadMaxSize  = 1024 * 1024
+)
+
+// benchmarkConcurrentStatRead creates fileCount files with random sizes
+// between statReadMinSize and statReadMaxSize, then has workers goroutines
+// pick files at random and os.Stat and os.ReadFile each one, the way a static
+// file server would, for statReadDuration.
+func benchmarkConcurrentStatRead(dir string, fileCount int, workers int) (*ConcurrentStatReadResult, error) {
+	names := make([]string, 0, fileCount)
+	defer func() {
+		for _, name := range names {
+			removeTemp(name)
+		}
+	}()
+
+	buf := make([]byte, statReadMaxSize)
+	if err := fillContent(buf); err != nil {
+		return nil, err
+	}
+
+	for range fileCount {
+		f, err := createTemp(dir, "stat_read_*")
+		if err != nil {
+			return nil, fmt.Errorf("create temp file: %w", err)
+		}
+		names = append(names, f.Name())
+
+		_, err = f.Write(buf[:statReadMinSize+rand.IntN(statReadMaxSize-statReadMinSize)])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+	}
+
+	var ops, bytes atomic.Int64
+	samples := make([][]time.Duration, workers)
+
+	var g errgroup.Group
+
+	start := time.Now()
+
+	for i := range workers {
+		g.Go(func() error {
+			for time.Since(start) < statReadDuration {
+				name := names[rand.IntN(len(names))]
+
+				statStart := time.Now()
+				if _, err := os.Stat(name); err != nil {
+					return fmt.Errorf("stat temp file: %w", err)
+				}
+				samples[i] = append(samples[i], time.Since(statStart))
+
+				b, err := os.ReadFile(name)
+				if err != nil {
+					return fmt.Errorf("read temp file: %w", err)
+				}
+
+				ops.Add(1)
+				bytes.Add(int64(len(b)))
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	since := time.Since(start)
+
+	return &ConcurrentStatReadResult{
+		Files:          fileCount,
+		Workers:        workers,
+		Ops:            ops.Load(),
+		ThroughputMBps: float64(bytes.Load()) / (1 << 20) / since.Seconds(),
+		StatLatency:    latencyStats(slices.Concat(samples...)),
+	}, nil
+}
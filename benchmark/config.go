@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is everything the server can be configured with. Every field has an
+// environment variable, noted next to it, and can be overridden by the YAML
+// file named by BM_CONFIG_FILE:
+//
+//	ephemeral_dir: /mnt/ephemeral
+//	persistent_dir: /mnt/persistent
+//	src_file_count: 12
+//	timeouts:
+//	  huge: 10m
+//	influxdb:
+//	  url: http://influxdb:8086
+//	  org: benchmarks
+//	  bucket: disk
+type Config struct {
+	EphemeralDir  string `yaml:"ephemeral_dir"`  // BM_EPHEMERAL_DIR
+	PersistentDir string `yaml:"persistent_dir"` // BM_PERSISTENT_DIR
+
+	// SrcFileCount is how many source files each size class copies from,
+	// 1 to maxSrcFileCount (15), as is the src_count query parameter. More
+	// files than that cannot be spread over the huge class without two of
+	// them coming within 5% of each other.
+	SrcFileCount int    `yaml:"src_file_count"` // BM_SRC_FILE_COUNT
+	ContentType  string `yaml:"content_type"`   // BM_CONTENT_TYPE
+
+	CopyBufferSize int `yaml:"copy_buffer_size"` // BM_COPY_BUFFER_SIZE
+	WarmupRuns     int `yaml:"warmup_runs"`      // BM_WARMUP_RUNS
+
+	// A size class whose per-file copy times have a coefficient of variation
+	// above MaxCV is run again, up to MaxRetries times.
+	MaxCV      float64 `yaml:"max_cv"`      // BM_MAX_CV
+	MaxRetries int     `yaml:"max_retries"` // BM_MAX_RETRIES
+
+	// VerifyWrites reads every copy back and compares its SHA-256 with the
+	// source, failing the run on the first mismatch.
+	VerifyWrites bool `yaml:"verify_writes"` // BM_VERIFY_WRITES
+
+	// NoCleanup keeps the source and destination files of the disk
+	// benchmarks for inspection. They pile up with every run.
+	NoCleanup bool `yaml:"no_cleanup"` // BM_NO_CLEANUP
+
+	Timeouts ClassTimeouts `yaml:"timeouts"`
+
+	InstanceType string `yaml:"instance_type"` // BM_INSTANCE_TYPE
+	Region       string `yaml:"region"`        // BM_REGION
+
+	InfluxDB       InfluxDBConfig `yaml:"influxdb"`
+	PushgatewayURL string         `yaml:"pushgateway_url"` // BM_PUSHGATEWAY_URL
+
+	// Network targets are host:port pairs, comma separated in the
+	// environment. IPv6 hosts are written in brackets.
+	NetworkTargets   []string `yaml:"network_targets"`    // BM_NETWORK_TARGETS
+	NetworkTargetsV6 []string `yaml:"network_targets_v6"` // BM_NETWORK_TARGETS_V6
+
+	// AllowedCommands are the command lines /exec-spawn may run, comma
+	// separated in the environment. Arguments are split on spaces.
+	AllowedCommands []string `yaml:"allowed_commands"` // BM_ALLOWED_COMMANDS
+
+	RedisURL string `yaml:"redis_url"` // BM_REDIS_URL
+
+	Server ServerConfig `yaml:"server"`
+	TLS    TLSConfig    `yaml:"tls"`
+
+	APIKey        string `yaml:"api_key"`        // BM_API_KEY
+	WebhookSecret string `yaml:"webhook_secret"` // BM_WEBHOOK_SECRET
+	PluginDir     string `yaml:"plugin_dir"`     // BM_PLUGIN_DIR
+	Debug         bool   `yaml:"debug"`          // BM_DEBUG
+}
+
+// ClassTimeouts bounds how long each size class may run. Zero means no limit.
+type ClassTimeouts struct {
+	Tiny   time.Duration `yaml:"tiny"`   // BM_TINY_TIMEOUT
+	Small  time.Duration `yaml:"small"`  // BM_SMALL_TIMEOUT
+	Medium time.Duration `yaml:"medium"` // BM_MEDIUM_TIMEOUT
+	Large  time.Duration `yaml:"large"`  // BM_LARGE_TIMEOUT
+	Huge   time.Duration `yaml:"huge"`   // BM_HUGE_TIMEOUT
+}
+
+func (t ClassTimeouts) get(name string) time.Duration {
+	switch name {
+	case "tiny":
+		return t.Tiny
+	case "small":
+		return t.Small
+	case "medium":
+		return t.Medium
+	case "large":
+		return t.Large
+	case "huge":
+		return t.Huge
+	}
+	return 0
+}
+
+// ServerConfig holds the http.Server fields of the same names. Zero values
+// mean what they mean to http.Server.
+type ServerConfig struct {
+	ReadTimeout       time.Duration `yaml:"read_timeout"`        // BM_READ_TIMEOUT
+	WriteTimeout      time.Duration `yaml:"write_timeout"`       // BM_WRITE_TIMEOUT
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`        // BM_IDLE_TIMEOUT
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"` // BM_READ_HEADER_TIMEOUT
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`    // BM_MAX_HEADER_BYTES
+}
+
+// TLSConfig switches the server to HTTPS, with either the given certificate
+// or one generated into the persistent directory.
+type TLSConfig struct {
+	CertFile   string `yaml:"cert_file"`   // BM_TLS_CERT_FILE
+	KeyFile    string `yaml:"key_file"`    // BM_TLS_KEY_FILE
+	SelfSigned bool   `yaml:"self_signed"` // BM_TLS_SELF_SIGNED
+}
+
+type InfluxDBConfig struct {
+	URL    string `yaml:"url"`    // BM_INFLUXDB_URL
+	Token  string `yaml:"token"`  // BM_INFLUXDB_TOKEN
+	Org    string `yaml:"org"`    // BM_INFLUXDB_ORG
+	Bucket string `yaml:"bucket"` // BM_INFLUXDB_BUCKET
+}
+
+// loadConfig reads the configuration from the environment and then applies
+// the file named by BM_CONFIG_FILE on top of it. A missing file is logged and
+// otherwise ignored.
+func loadConfig() (*Config, error) {
+	cfg, err := configFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	if path := os.Getenv("BM_CONFIG_FILE"); path != "" {
+		b, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Println("config file", path, "not found, using the environment only")
+		} else if err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		} else if err := cfg.merge(b); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func configFromEnv() (*Config, error) {
+	cfg := &Config{
+		EphemeralDir:  os.Getenv("BM_EPHEMERAL_DIR"),
+		PersistentDir: os.Getenv("BM_PERSISTENT_DIR"),
+		SrcFileCount:  10,
+		ContentType:   os.Getenv("BM_CONTENT_TYPE"),
+
+		CopyBufferSize: 32 * 1024,
+		MaxCV:          0.05,
+		MaxRetries:     3,
+
+		Server: ServerConfig{
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Minute,
+			IdleTimeout:  60 * time.Second,
+		},
+
+		InstanceType: os.Getenv("BM_INSTANCE_TYPE"),
+		Region:       os.Getenv("BM_REGION"),
+
+		InfluxDB: InfluxDBConfig{
+			URL:    os.Getenv("BM_INFLUXDB_URL"),
+			Token:  os.Getenv("BM_INFLUXDB_TOKEN"),
+			Org:    os.Getenv("BM_INFLUXDB_ORG"),
+			Bucket: os.Getenv("BM_INFLUXDB_BUCKET"),
+		},
+		TLS: TLSConfig{
+			CertFile:   os.Getenv("BM_TLS_CERT_FILE"),
+			KeyFile:    os.Getenv("BM_TLS_KEY_FILE"),
+			SelfSigned: os.Getenv("BM_TLS_SELF_SIGNED") == "true",
+		},
+
+		PushgatewayURL: os.Getenv("BM_PUSHGATEWAY_URL"),
+
+		NetworkTargets:   splitList(os.Getenv("BM_NETWORK_TARGETS")),
+		NetworkTargetsV6: splitList(os.Getenv("BM_NETWORK_TARGETS_V6")),
+		AllowedCommands:  []string{"/bin/true"},
+		RedisURL:         os.Getenv("BM_REDIS_URL"),
+
+		APIKey:        os.Getenv("BM_API_KEY"),
+		WebhookSecret: os.Getenv("BM_WEBHOOK_SECRET"),
+		PluginDir:     os.Getenv("BM_PLUGIN_DIR"),
+		Debug:         os.Getenv("BM_DEBUG") == "true",
+
+		VerifyWrites: os.Getenv("BM_VERIFY_WRITES") == "true",
+		NoCleanup:    os.Getenv("BM_NO_CLEANUP") == "true",
+	}
+
+	if v := os.Getenv("BM_ALLOWED_COMMANDS"); v != "" {
+		cfg.AllowedCommands = splitList(v)
+	}
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"BM_SRC_FILE_COUNT", &cfg.SrcFileCount},
+		{"BM_COPY_BUFFER_SIZE", &cfg.CopyBufferSize},
+		{"BM_WARMUP_RUNS", &cfg.WarmupRuns},
+		{"BM_MAX_RETRIES", &cfg.MaxRetries},
+		{"BM_MAX_HEADER_BYTES", &cfg.Server.MaxHeaderBytes},
+	}
+	for _, i := range ints {
+		if v := os.Getenv(i.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("envvar %s: %w", i.name, err)
+			}
+			*i.dst = n
+		}
+	}
+
+	durations := []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"BM_TINY_TIMEOUT", &cfg.Timeouts.Tiny},
+		{"BM_SMALL_TIMEOUT", &cfg.Timeouts.Small},
+		{"BM_MEDIUM_TIMEOUT", &cfg.Timeouts.Medium},
+		{"BM_LARGE_TIMEOUT", &cfg.Timeouts.Large},
+		{"BM_HUGE_TIMEOUT", &cfg.Timeouts.Huge},
+		{"BM_READ_TIMEOUT", &cfg.Server.ReadTimeout},
+		{"BM_WRITE_TIMEOUT", &cfg.Server.WriteTimeout},
+		{"BM_IDLE_TIMEOUT", &cfg.Server.IdleTimeout},
+		{"BM_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout},
+	}
+	for _, t := range durations {
+		if v := os.Getenv(t.name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("envvar %s: %w", t.name, err)
+			}
+			*t.dst = d
+		}
+	}
+
+	if v := os.Getenv("BM_MAX_CV"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("envvar BM_MAX_CV: %w", err)
+		}
+		cfg.MaxCV = f
+	}
+
+	return cfg, nil
+}
+
+// splitList splits a comma separated environment variable, dropping empty
+// entries.
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// merge overrides the fields of cfg that are set in the YAML document b.
+// Unknown keys are rejected so typos do not go unnoticed.
+func (cfg *Config) merge(b []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate reports the first problem found in cfg.
+func (cfg *Config) Validate() error {
+	if cfg.EphemeralDir == "" {
+		return fmt.Errorf("config: ephemeral_dir (BM_EPHEMERAL_DIR) must be set")
+	}
+	if cfg.PersistentDir == "" {
+		return fmt.Errorf("config: persistent_dir (BM_PERSISTENT_DIR) must be set")
+	}
+	if cfg.SrcFileCount < 1 || cfg.SrcFileCount > maxSrcFileCount {
+		return fmt.Errorf("config: src_file_count (BM_SRC_FILE_COUNT) must be between 1 and %d", maxSrcFileCount)
+	}
+
+	if n := cfg.CopyBufferSize; n < 4096 || n > 16*1024*1024 || n&(n-1) != 0 {
+		return fmt.Errorf("config: copy_buffer_size (BM_COPY_BUFFER_SIZE) must be a power of two between 4096 and 16777216")
+	}
+
+	if cfg.WarmupRuns < 0 {
+		return fmt.Errorf("config: warmup_runs (BM_WARMUP_RUNS) must not be negative")
+	}
+	if cfg.MaxCV < 0 {
+		return fmt.Errorf("config: max_cv (BM_MAX_CV) must not be negative")
+	}
+	if cfg.MaxRetries < 0 {
+		return fmt.Errorf("config: max_retries (BM_MAX_RETRIES) must not be negative")
+	}
+
+	switch cfg.ContentType {
+	case "", "random", "zero", "pattern":
+	default:
+		return fmt.Errorf("config: content_type (BM_CONTENT_TYPE) must be one of random, zero or pattern")
+	}
+
+	for _, class := range defaultSizeClasses() {
+		if cfg.Timeouts.get(class.Name) < 0 {
+			return fmt.Errorf("config: timeouts.%s must not be negative", class.Name)
+		}
+	}
+
+	if cfg.Server.ReadTimeout < 0 || cfg.Server.WriteTimeout < 0 || cfg.Server.IdleTimeout < 0 || cfg.Server.ReadHeaderTimeout < 0 {
+		return fmt.Errorf("config: server timeouts must not be negative")
+	}
+	if cfg.Server.MaxHeaderBytes < 0 {
+		return fmt.Errorf("config: server.max_header_bytes (BM_MAX_HEADER_BYTES) must not be negative")
+	}
+
+	for _, target := range slices.Concat(cfg.NetworkTargets, cfg.NetworkTargetsV6) {
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			return fmt.Errorf("config: network target %q: %w", target, err)
+		}
+	}
+
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return fmt.Errorf("config: tls.cert_file (BM_TLS_CERT_FILE) and tls.key_file (BM_TLS_KEY_FILE) must be set together")
+	}
+
+	if cfg.InfluxDB.URL != "" && (cfg.InfluxDB.Org == "" || cfg.InfluxDB.Bucket == "") {
+		return fmt.Errorf("config: influxdb.org (BM_INFLUXDB_ORG) and influxdb.bucket (BM_INFLUXDB_BUCKET) must be set with influxdb.url")
+	}
+
+	return nil
+}
+
+// applyConfig sets up the package state the handlers run with.
+func applyConfig(cfg *Config) error {
+	ephemeralDir = cfg.EphemeralDir
+	persistentDir = cfg.PersistentDir
+	srcFileCount = cfg.SrcFileCount
+	copyBufferSize = cfg.CopyBufferSize
+	warmupRuns = cfg.WarmupRuns
+	maxCV = cfg.MaxCV
+	maxRetries = cfg.MaxRetries
+	verifyWrites = cfg.VerifyWrites
+
+	noCleanup = cfg.NoCleanup
+	if noCleanup {
+		log.Println("WARNING: BM_NO_CLEANUP is set, disk benchmark files are kept and will fill", ephemeralDir, "and", persistentDir)
+	}
+	classTimeouts = cfg.Timeouts
+
+	contentType = cfg.ContentType
+	if contentType == "" {
+		contentType = "random"
+	}
+
+	networkTargets = cfg.NetworkTargets
+	networkTargetsV6 = cfg.NetworkTargetsV6
+	allowedCommands = cfg.AllowedCommands
+
+	cloudMeta = CloudMeta{cfg.InstanceType, cfg.Region}
+	apiKey = cfg.APIKey
+	webhookSecret = cfg.WebhookSecret
+
+	resultStore = NewResultStore(filepath.Join(persistentDir, "results.ndjson"))
+	influxDBExporter = newInfluxDBExporter(cfg.InfluxDB)
+	pushgatewayExporter = newPushgatewayExporter(cfg.PushgatewayURL)
+
+	var err error
+	if benchmarkLock, err = newBenchmarkLock(cfg.RedisURL); err != nil {
+		return err
+	}
+
+	return nil
+}
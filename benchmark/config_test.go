@@ -0,0 +1,173 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupConfigEnv sets the directories loadConfig requires and clears
+// BM_CONFIG_FILE, so only what a test sets on top is read.
+func setupConfigEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("BM_CONFIG_FILE", "")
+	t.Setenv("BM_EPHEMERAL_DIR", "/mnt/ephemeral")
+	t.Setenv("BM_PERSISTENT_DIR", "/mnt/persistent")
+}
+
+// writeConfigFile writes a YAML config file into a temp dir and points
+// BM_CONFIG_FILE at it.
+func writeConfigFile(t *testing.T, body string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BM_CONFIG_FILE", path)
+}
+
+func TestLoadConfigEnv(t *testing.T) {
+	setupConfigEnv(t)
+	t.Setenv("BM_SRC_FILE_COUNT", "5")
+	t.Setenv("BM_MAX_CV", "0.1")
+	t.Setenv("BM_HUGE_TIMEOUT", "2m")
+	t.Setenv("BM_NETWORK_TARGETS", "a:80, b:443,")
+	t.Setenv("BM_VERIFY_WRITES", "true")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.EphemeralDir != "/mnt/ephemeral" || cfg.PersistentDir != "/mnt/persistent" {
+		t.Errorf("dirs = %q, %q", cfg.EphemeralDir, cfg.PersistentDir)
+	}
+	if cfg.SrcFileCount != 5 {
+		t.Errorf("SrcFileCount = %d, want 5", cfg.SrcFileCount)
+	}
+	if cfg.MaxCV != 0.1 {
+		t.Errorf("MaxCV = %v, want 0.1", cfg.MaxCV)
+	}
+	if cfg.Timeouts.Huge != 2*time.Minute {
+		t.Errorf("Timeouts.Huge = %v, want 2m", cfg.Timeouts.Huge)
+	}
+	if got := strings.Join(cfg.NetworkTargets, " "); got != "a:80 b:443" {
+		t.Errorf("NetworkTargets = %q, want [a:80 b:443]", cfg.NetworkTargets)
+	}
+	if !cfg.VerifyWrites {
+		t.Error("VerifyWrites = false, want true")
+	}
+	if cfg.CopyBufferSize != 32*1024 || cfg.MaxRetries != 3 {
+		t.Errorf("defaults: CopyBufferSize = %d, MaxRetries = %d", cfg.CopyBufferSize, cfg.MaxRetries)
+	}
+}
+
+func TestLoadConfigEnvInvalid(t *testing.T) {
+	setupConfigEnv(t)
+	t.Setenv("BM_SRC_FILE_COUNT", "ten")
+
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "BM_SRC_FILE_COUNT") {
+		t.Errorf("loadConfig() error = %v, want one naming BM_SRC_FILE_COUNT", err)
+	}
+}
+
+func TestLoadConfigFileOverridesEnv(t *testing.T) {
+	setupConfigEnv(t)
+	t.Setenv("BM_SRC_FILE_COUNT", "5")
+	t.Setenv("BM_REGION", "eu-north-1")
+	writeConfigFile(t, `
+ephemeral_dir: /data/ephemeral
+src_file_count: 12
+timeouts:
+  huge: 10m
+`)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.EphemeralDir != "/data/ephemeral" {
+		t.Errorf("EphemeralDir = %q, want the file's /data/ephemeral", cfg.EphemeralDir)
+	}
+	if cfg.SrcFileCount != 12 {
+		t.Errorf("SrcFileCount = %d, want the file's 12", cfg.SrcFileCount)
+	}
+	if cfg.Timeouts.Huge != 10*time.Minute {
+		t.Errorf("Timeouts.Huge = %v, want 10m", cfg.Timeouts.Huge)
+	}
+	if cfg.PersistentDir != "/mnt/persistent" || cfg.Region != "eu-north-1" {
+		t.Errorf("fields left out of the file: PersistentDir = %q, Region = %q, want the environment's", cfg.PersistentDir, cfg.Region)
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	setupConfigEnv(t)
+	t.Setenv("BM_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	if _, err := loadConfig(); err != nil {
+		t.Errorf("loadConfig() with a missing file = %v, want nil", err)
+	}
+}
+
+func TestLoadConfigFileUnknownKey(t *testing.T) {
+	setupConfigEnv(t)
+	writeConfigFile(t, "src_fil_count: 12\n")
+
+	_, err := loadConfig()
+	if err == nil || !strings.Contains(err.Error(), "src_fil_count") {
+		t.Errorf("loadConfig() error = %v, want one naming src_fil_count", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{"valid", func(cfg *Config) {}, ""},
+		{"no ephemeral dir", func(cfg *Config) { cfg.EphemeralDir = "" }, "BM_EPHEMERAL_DIR"},
+		{"no persistent dir", func(cfg *Config) { cfg.PersistentDir = "" }, "BM_PERSISTENT_DIR"},
+		{"zero src count", func(cfg *Config) { cfg.SrcFileCount = 0 }, "BM_SRC_FILE_COUNT"},
+		{"src count above max", func(cfg *Config) { cfg.SrcFileCount = maxSrcFileCount + 1 }, "BM_SRC_FILE_COUNT"},
+		{"copy buffer not a power of two", func(cfg *Config) { cfg.CopyBufferSize = 5000 }, "BM_COPY_BUFFER_SIZE"},
+		{"copy buffer too small", func(cfg *Config) { cfg.CopyBufferSize = 2048 }, "BM_COPY_BUFFER_SIZE"},
+		{"negative warmup runs", func(cfg *Config) { cfg.WarmupRuns = -1 }, "BM_WARMUP_RUNS"},
+		{"negative max cv", func(cfg *Config) { cfg.MaxCV = -0.1 }, "BM_MAX_CV"},
+		{"negative max retries", func(cfg *Config) { cfg.MaxRetries = -1 }, "BM_MAX_RETRIES"},
+		{"unknown content type", func(cfg *Config) { cfg.ContentType = "ones" }, "BM_CONTENT_TYPE"},
+		{"negative class timeout", func(cfg *Config) { cfg.Timeouts.Large = -time.Second }, "timeouts.large"},
+		{"negative server timeout", func(cfg *Config) { cfg.Server.IdleTimeout = -time.Second }, "server timeouts"},
+		{"network target without port", func(cfg *Config) { cfg.NetworkTargets = []string{"example.com"} }, "example.com"},
+		{"tls cert without key", func(cfg *Config) { cfg.TLS.CertFile = "cert.pem" }, "BM_TLS_KEY_FILE"},
+		{"influxdb url without bucket", func(cfg *Config) { cfg.InfluxDB.URL = "http://influxdb:8086" }, "BM_INFLUXDB_BUCKET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupConfigEnv(t)
+
+			cfg, err := configFromEnv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(cfg)
+
+			err = cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"fmt"
+)
+
+// contentType selects what the disk benchmarks write, set with
+// BM_CONTENT_TYPE or content_type in the config file:
+//
+//   - random: bytes from crypto/rand (the default)
+//   - zero: all zero bytes
+//   - pattern: the bytes 0 to 255 repeated
+//
+// zero and pattern take the CSPRNG out of the measurement, which matters on
+// machines without hardware RNG acceleration.
+var contentType = "random"
+
+// fillContent overwrites buf with benchmark content of the configured type.
+func fillContent(buf []byte) error {
+	switch contentType {
+	case "zero":
+		clear(buf)
+	case "pattern":
+		for i := range buf {
+			buf[i] = byte(i)
+		}
+	default:
+		if _, err := crand.Read(buf); err != nil {
+			return fmt.Errorf("random bytes: %w", err)
+		}
+	}
+	return nil
+}
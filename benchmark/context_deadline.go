@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func benchContextDeadline(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		ContextDeadlineResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	count, err := queryInt(r, "count", 1000000, 1, 1000000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.ContextDeadlineResult = *benchmarkContextDeadline(count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// ContextDeadlineResult is the cost of the context calls that deadline aware
+// code makes, and of serving a request through http.TimeoutHandler compared
+// to calling the handler directly.
+type ContextDeadlineResult struct {
+	Count                 int
+	DeadlineNsPerOp       float64
+	ErrNsPerOp            float64
+	DoneNsPerOp           float64
+	HandlerNsPerOp        float64
+	TimeoutHandlerNsPerOp float64
+}
+
+// benchmarkContextDeadline calls Deadline, Err and Done count times each on a
+// context with a deadline an hour away, so it never expires during the run.
+// It then serves count requests to an empty handler, directly and wrapped in
+// http.TimeoutHandler.
+func benchmarkContextDeadline(count int) *ContextDeadlineResult {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Hour))
+	defer cancel()
+
+	res := &ContextDeadlineResult{Count: count}
+
+	var deadline time.Time
+	var ctxErr error
+	var done <-chan struct{}
+
+	ops := []struct {
+		nsPerOp *float64
+		fn      func()
+	}{
+		{&res.DeadlineNsPerOp, func() { deadline, _ = ctx.Deadline() }},
+		{&res.ErrNsPerOp, func() { ctxErr = ctx.Err() }},
+		{&res.DoneNsPerOp, func() { done = ctx.Done() }},
+	}
+
+	for _, op := range ops {
+		start := time.Now()
+		for range count {
+			op.fn()
+		}
+		*op.nsPerOp = float64(time.Since(start).Nanoseconds()) / float64(count)
+	}
+
+	keep(deadline)
+	keep(ctxErr)
+	keep(done)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	req := httptest.NewRequest("GET", "/", nil)
+
+	for _, h := range []struct {
+		nsPerOp *float64
+		handler http.Handler
+	}{
+		{&res.HandlerNsPerOp, handler},
+		{&res.TimeoutHandlerNsPerOp, http.TimeoutHandler(handler, time.Hour, "")},
+	} {
+		start := time.Now()
+		for range count {
+			h.handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		*h.nsPerOp = float64(time.Since(start).Nanoseconds()) / float64(count)
+	}
+
+	return res
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+func benchContextOverhead(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		ContextOverheadResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	depth, err := queryInt(r, "depth", 10, 1, 10000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000000, 1, 1000000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.ContextOverheadResult = *benchmarkContextPropagation(depth, count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type ContextOverheadResult struct {
+	Depth             int
+	Count             int
+	NestedNsPerOp     float64
+	BackgroundNsPerOp float64
+	Slowdown          float64
+}
+
+type contextOverheadKey int
+
+// benchmarkContextPropagation nests depth context.WithValue contexts and looks
+// up the outermost key from the innermost context count times, which walks the
+// whole chain on every call. The same number of lookups on
+// context.Background() is the baseline.
+func benchmarkContextPropagation(depth int, count int) *ContextOverheadResult {
+	ctx := context.Background()
+	for i := range depth {
+		ctx = context.WithValue(ctx, contextOverheadKey(i), i)
+	}
+
+	nested := timeContextLookups(ctx, count)
+	background := timeContextLookups(context.Background(), count)
+
+	res := &ContextOverheadResult{
+		Depth:             depth,
+		Count:             count,
+		NestedNsPerOp:     float64(nested) / float64(count),
+		BackgroundNsPerOp: float64(background) / float64(count),
+	}
+	if res.BackgroundNsPerOp > 0 {
+		res.Slowdown = res.NestedNsPerOp / res.BackgroundNsPerOp
+	}
+
+	return res
+}
+
+func timeContextLookups(ctx context.Context, count int) time.Duration {
+	var v any
+	start := time.Now()
+
+	for range count {
+		v = ctx.Value(contextOverheadKey(0))
+	}
+
+	since := time.Since(start)
+	keep(v)
+	return since
+}
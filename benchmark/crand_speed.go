@@ -0,0 +1,76 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func benchCRandSpeed(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		CRandResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	sizeGB, err := queryFloat(r, "size_gb", 1, 0.001, 100)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkCRandRead(sizeGB)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.CRandResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type CRandResult struct {
+	Bytes   int64
+	Seconds float32
+	GBps    float64
+	Warning string `json:",omitempty"`
+}
+
+// crandSlowGBps is the throughput below which crypto/rand is likely to hold
+// back the disk benchmarks when they write random content.
+const crandSlowGBps = 1
+
+func benchmarkCRandRead(sizeGB float64) (*CRandResult, error) {
+	total := int64(sizeGB * (1 << 30))
+	buf := make([]byte, 1024*1024)
+
+	start := time.Now()
+
+	for read := int64(0); read < total; {
+		n, err := crand.Read(buf[:min(int64(len(buf)), total-read)])
+		if err != nil {
+			return nil, fmt.Errorf("random bytes: %w", err)
+		}
+		read += int64(n)
+	}
+
+	since := time.Since(start)
+
+	res := &CRandResult{
+		Bytes:   total,
+		Seconds: float32(since) / float32(time.Second),
+		GBps:    float64(total) / (1 << 30) / since.Seconds(),
+	}
+
+	if res.GBps < crandSlowGBps {
+		res.Warning = fmt.Sprintf("crypto/rand produces %.2f GB/s, which may bottleneck disk benchmarks; consider BM_CONTENT_TYPE=zero or pattern", res.GBps)
+	}
+
+	return res, nil
+}
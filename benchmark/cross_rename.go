@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+func benchCrossRename(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		CrossRenameResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	sizeKB, err := queryInt(r, "size_kb", 64, 1, 1024*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkCrossRename(ephemeralDir, persistentDir, sizeKB*1024, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.CrossRenameResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type CrossRenameResult struct {
+	FileSize      int
+	Count         int
+	CrossDevice   bool
+	RenameNsPerOp float64
+	Latency       LatencyStats
+	Warning       string `json:",omitempty"`
+}
+
+// benchmarkCrossRename moves count files of fileSize bytes from a temp dir in
+// src to one in dst. os.Rename fails with EXDEV across devices, so there the
+// move falls back to copying and deleting, the way mv does, and that is what
+// gets timed. Only the moves are timed, not writing the files.
+func benchmarkCrossRename(src string, dst string, fileSize int, count int) (*CrossRenameResult, error) {
+	crossDevice, err := isCrossDevice(src, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	srcTmp, err := mkdirTemp(src, "cross_rename_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(srcTmp)
+
+	dstTmp, err := mkdirTemp(dst, "cross_rename_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(dstTmp)
+
+	buf := make([]byte, fileSize)
+	if err := fillContent(buf); err != nil {
+		return nil, err
+	}
+
+	samples := make([]time.Duration, 0, count)
+	var total time.Duration
+
+	for i := range count {
+		name := fmt.Sprintf("file_%d", i)
+		from, to := filepath.Join(srcTmp, name), filepath.Join(dstTmp, name)
+
+		if err := os.WriteFile(from, buf, 0o644); err != nil {
+			return nil, fmt.Errorf("write file: %w", err)
+		}
+
+		start := time.Now()
+		if err := moveFile(from, to); err != nil {
+			return nil, err
+		}
+		since := time.Since(start)
+
+		samples = append(samples, since)
+		total += since
+	}
+
+	res := &CrossRenameResult{
+		FileSize:      fileSize,
+		Count:         count,
+		CrossDevice:   crossDevice,
+		RenameNsPerOp: float64(total.Nanoseconds()) / float64(count),
+		Latency:       latencyStats(samples),
+	}
+
+	if crossDevice {
+		res.Warning = fmt.Sprintf("%s and %s are on different devices, so renames between them copy the data", src, dst)
+	}
+
+	return res, nil
+}
+
+// isCrossDevice reports whether a and b are on different devices.
+func isCrossDevice(a, b string) (bool, error) {
+	var sa, sb syscall.Stat_t
+	if err := syscall.Stat(a, &sa); err != nil {
+		return false, fmt.Errorf("stat %s: %w", a, err)
+	}
+	if err := syscall.Stat(b, &sb); err != nil {
+		return false, fmt.Errorf("stat %s: %w", b, err)
+	}
+	return sa.Dev != sb.Dev, nil
+}
+
+// moveFile renames from to to, copying and removing from when they are on
+// different devices.
+func moveFile(from, to string) error {
+	err := os.Rename(from, to)
+	if !errors.Is(err, syscall.EXDEV) {
+		if err != nil {
+			return fmt.Errorf("rename: %w", err)
+		}
+		return nil
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return fmt.Errorf("open src file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(to)
+	if err != nil {
+		return fmt.Errorf("create dest file: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("copy file: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("copy file: %w", err)
+	}
+
+	if err := os.Remove(from); err != nil {
+		return fmt.Errorf("remove src file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func benchCSV(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		CSVResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	rows, err := queryInt(r, "rows", 10000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	cols, err := queryInt(r, "cols", 10, 1, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if rows*cols > maxCSVCells {
+		http.Error(w, fmt.Sprintf("query parameters rows and cols: at most %d cells", maxCSVCells), 400)
+		return
+	}
+
+	res, err := benchmarkCSV(rows, cols)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.CSVResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// CSVResult counts a row as one op. JSONReadOpsPerSec decodes the same rows
+// as a JSON array of string arrays.
+type CSVResult struct {
+	Rows              int
+	Cols              int
+	CSVBytes          int
+	JSONBytes         int
+	WriteOpsPerSec    float64
+	ReadOpsPerSec     float64
+	JSONReadOpsPerSec float64
+	// CSVReadSpeedup is how many times faster the CSV was read than the JSON.
+	CSVReadSpeedup float64
+}
+
+const maxCSVCells = 10000000
+
+// benchmarkCSV writes rows records of cols fields with csv.Writer and reads
+// them back with csv.Reader. For comparison the same records are encoded as
+// JSON up front and decoded with json.Unmarshal.
+func benchmarkCSV(rows int, cols int) (*CSVResult, error) {
+	records := make([][]string, rows)
+	for i := range records {
+		records[i] = make([]string, cols)
+		for j := range records[i] {
+			records[i][j] = "r" + strconv.Itoa(i) + "c" + strconv.Itoa(j)
+		}
+	}
+
+	res := &CSVResult{Rows: rows, Cols: cols}
+
+	var buf bytes.Buffer
+	start := time.Now()
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		return nil, &StepError{"write", fmt.Errorf("write csv: %w", err)}
+	}
+	res.WriteOpsPerSec = float64(rows) / time.Since(start).Seconds()
+	res.CSVBytes = buf.Len()
+
+	start = time.Now()
+	read, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		return nil, &StepError{"read", fmt.Errorf("read csv: %w", err)}
+	}
+	res.ReadOpsPerSec = float64(rows) / time.Since(start).Seconds()
+	if len(read) != rows {
+		return nil, &StepError{"read", fmt.Errorf("read %d csv rows, want %d", len(read), rows)}
+	}
+
+	b, err := json.Marshal(records)
+	if err != nil {
+		return nil, &StepError{"json", fmt.Errorf("encode json: %w", err)}
+	}
+	res.JSONBytes = len(b)
+
+	var decoded [][]string
+	start = time.Now()
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, &StepError{"json", fmt.Errorf("decode json: %w", err)}
+	}
+	res.JSONReadOpsPerSec = float64(rows) / time.Since(start).Seconds()
+
+	res.CSVReadSpeedup = res.ReadOpsPerSec / res.JSONReadOpsPerSec
+
+	return res, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+func benchDeflate(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DeflateResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	level, err := queryInt(r, "level", flate.DefaultCompression, flate.HuffmanOnly, flate.BestCompression)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeMB, err := queryInt(r, "size_mb", 64, 1, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkDeflate(level, sizeMB)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.DeflateResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// DeflateResult compares raw deflate with gzip, which is the same deflate
+// stream with a header and a CRC-32 trailer.
+type DeflateResult struct {
+	Level int
+	Bytes int
+	Flate CompressionRun
+	Gzip  CompressionRun
+}
+
+// CompressionRun measures throughput on the uncompressed size. Ratio is the
+// uncompressed size divided by the compressed size.
+type CompressionRun struct {
+	CompressGBps   float64
+	DecompressGBps float64
+	Ratio          float64
+}
+
+// benchmarkDeflate compresses and decompresses inputMB MB with compress/flate
+// and compress/gzip at level. The input is the JSON of the serialization
+// benchmark's large sample, repeated, rather than fillContent output: random
+// content does not compress at all, and zero and pattern content compress to
+// almost nothing, so none of them resemble real payloads. zstd is left out
+// because it is not in the standard library.
+func benchmarkDeflate(level int, inputMB int) (*DeflateResult, error) {
+	sample, err := json.Marshal(serialSampleSizes["large"]())
+	if err != nil {
+		return nil, fmt.Errorf("encode sample: %w", err)
+	}
+
+	size := inputMB * 1024 * 1024
+	input := bytes.Repeat(sample, size/len(sample)+1)[:size]
+
+	res := &DeflateResult{Level: level, Bytes: size}
+
+	if res.Flate, err = runCompression(input,
+		func(w io.Writer) (io.WriteCloser, error) { return flate.NewWriter(w, level) },
+		func(r io.Reader) (io.ReadCloser, error) { return flate.NewReader(r), nil },
+	); err != nil {
+		return nil, &StepError{"flate", err}
+	}
+
+	if res.Gzip, err = runCompression(input,
+		func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriterLevel(w, level) },
+		func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	); err != nil {
+		return nil, &StepError{"gzip", err}
+	}
+
+	return res, nil
+}
+
+func runCompression(
+	input []byte,
+	newWriter func(io.Writer) (io.WriteCloser, error),
+	newReader func(io.Reader) (io.ReadCloser, error),
+) (CompressionRun, error) {
+	var compressed bytes.Buffer
+	gb := float64(len(input)) / (1 << 30)
+
+	start := time.Now()
+	zw, err := newWriter(&compressed)
+	if err != nil {
+		return CompressionRun{}, fmt.Errorf("create writer: %w", err)
+	}
+	if _, err := zw.Write(input); err != nil {
+		return CompressionRun{}, fmt.Errorf("compress: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return CompressionRun{}, fmt.Errorf("compress: %w", err)
+	}
+	compressTime := time.Since(start)
+
+	ratio := float64(len(input)) / float64(compressed.Len())
+
+	start = time.Now()
+	zr, err := newReader(&compressed)
+	if err != nil {
+		return CompressionRun{}, fmt.Errorf("create reader: %w", err)
+	}
+	n, err := io.Copy(io.Discard, zr)
+	if err != nil {
+		return CompressionRun{}, fmt.Errorf("decompress: %w", err)
+	}
+	if err := zr.Close(); err != nil {
+		return CompressionRun{}, fmt.Errorf("decompress: %w", err)
+	}
+	decompressTime := time.Since(start)
+
+	if n != int64(len(input)) {
+		return CompressionRun{}, fmt.Errorf("decompressed %d bytes, want %d", n, len(input))
+	}
+
+	return CompressionRun{
+		CompressGBps:   gb / compressTime.Seconds(),
+		DecompressGBps: gb / decompressTime.Seconds(),
+		Ratio:          ratio,
+	}, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+func benchDialLatency(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DialLatencyResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	targets := slices.Concat(networkTargets, networkTargetsV6)
+
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		if len(targets) == 0 {
+			http.Error(w, "query parameter target: no network targets are configured (BM_NETWORK_TARGETS)", 400)
+			return
+		}
+		target = targets[0]
+	}
+
+	// Only the configured network targets may be dialled, so the endpoint
+	// cannot be used to probe arbitrary hosts.
+	if !slices.Contains(targets, target) {
+		http.Error(w, fmt.Sprintf("query parameter target: %q is not a configured network target", target), 403)
+		return
+	}
+
+	count, err := queryInt(r, "count", 5, 1, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkDialLatency(r.Context(), target, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.DialLatencyResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// DialLatencyResult splits dialling target into its DNS lookup (Resolve) and
+// the TCP handshake to the first address found (Connect). Dial is
+// net.DialTimeout on the host name, which does both.
+type DialLatencyResult struct {
+	Target  string
+	Count   int
+	Resolve LatencyStats
+	Connect LatencyStats
+	Dial    LatencyStats
+}
+
+// benchmarkDialLatency times count lookups of target's host, count connects
+// to the address it resolves to and count dials of target itself. Every
+// connection is closed as soon as it is established.
+func benchmarkDialLatency(ctx context.Context, target string, count int) (*DialLatencyResult, error) {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return nil, fmt.Errorf("split target: %w", err)
+	}
+
+	res := &DialLatencyResult{Target: target, Count: count}
+
+	resolve := make([]time.Duration, 0, count)
+	var addrs []net.IPAddr
+
+	for range count {
+		start := time.Now()
+		addrs, err = net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, &StepError{"resolve", fmt.Errorf("look up %s: %w", host, err)}
+		}
+		resolve = append(resolve, time.Since(start))
+	}
+	res.Resolve = latencyStats(resolve)
+
+	addr := net.JoinHostPort(addrs[0].IP.String(), port)
+	if res.Connect, err = connectLatency("tcp", addr, count); err != nil {
+		return nil, &StepError{"connect", err}
+	}
+
+	if res.Dial, err = connectLatency("tcp", target, count); err != nil {
+		return nil, &StepError{"dial", err}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"syscall"
+)
+
+// diskInfoHandler reports capacity and filesystem details for both benchmark
+// directories without running any benchmark.
+func diskInfoHandler(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		Ephemeral  DiskInfo
+		Persistent DiskInfo
+	}
+
+	var response Response
+
+	var err error
+	if response.Ephemeral, err = diskInfo(ephemeralDir); err != nil {
+		fmt.Println(requestIDFromContext(r.Context()), err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+	if response.Persistent, err = diskInfo(persistentDir); err != nil {
+		fmt.Println(requestIDFromContext(r.Context()), err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type DiskInfo struct {
+	Path       string
+	TotalBytes uint64
+	FreeBytes  uint64
+	UsedBytes  uint64
+	Total      string
+	Free       string
+	Used       string
+	BlockSize  int64
+	Filesystem FilesystemInfo
+}
+
+// diskInfo reports the capacity of the filesystem holding dir. Free space is
+// what is available to unprivileged users, so Used plus Free can be less than
+// Total when blocks are reserved for root.
+func diskInfo(dir string) (DiskInfo, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return DiskInfo{}, fmt.Errorf("statfs %s: %w", dir, err)
+	}
+
+	bsize := uint64(st.Bsize)
+	total := st.Blocks * bsize
+	free := st.Bavail * bsize
+	used := (st.Blocks - st.Bfree) * bsize
+
+	return DiskInfo{
+		Path:       dir,
+		TotalBytes: total,
+		FreeBytes:  free,
+		UsedBytes:  used,
+		Total:      formatGiB(total),
+		Free:       formatGiB(free),
+		Used:       formatGiB(used),
+		BlockSize:  int64(st.Bsize),
+		Filesystem: detectFilesystemType(dir),
+	}, nil
+}
+
+func formatGiB(bytes uint64) string {
+	return fmt.Sprintf("%.2f GiB", float64(bytes)/(1<<30))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// StepError records which step of a benchmark, such as a disk size class,
+// failed.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return e.Step + ": " + e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// errorStep returns the step recorded in err's chain, if any.
+func errorStep(err error) string {
+	var se *StepError
+	if errors.As(err, &se) {
+		return se.Step
+	}
+	return ""
+}
+
+// writeJSONError writes the error response shared by all handlers. cause is
+// the innermost error in err's chain, for example "no space left on device",
+// which lets clients tell disk-full apart from permission or I/O errors.
+func writeJSONError(w http.ResponseWriter, status int, err error, step string) {
+	type Response struct {
+		Error string `json:"error"`
+		Step  string `json:"step"`
+		Cause string `json:"cause"`
+	}
+
+	cause := err
+	for {
+		next := errors.Unwrap(cause)
+		if next == nil {
+			break
+		}
+		cause = next
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+
+	if b, err := json.Marshal(Response{err.Error(), step, cause.Error()}); err == nil {
+		w.Write(b)
+	}
+}
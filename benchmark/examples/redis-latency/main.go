@@ -0,0 +1,104 @@
+// Command redis-latency is an example benchmark plugin that measures Redis
+// round-trip latency. Build it from the benchmark module with
+//
+//	go build -buildmode=plugin -o $BM_PLUGIN_DIR/redis-latency.so ./examples/redis-latency
+//
+// and point it at a server with BM_PLUGIN_REDIS_URL. It serves
+// /redis-latency?count=N.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"benchmark/pkg/plugin"
+)
+
+func Register(mux *http.ServeMux, meta plugin.BenchmarkMeta) {
+	mux.HandleFunc("/redis-latency", meta.Exclusive(benchRedisLatency))
+}
+
+type Result struct {
+	Count int
+	MinMs float64
+	P50Ms float64
+	P99Ms float64
+	MaxMs float64
+}
+
+func benchRedisLatency(w http.ResponseWriter, r *http.Request) {
+	count := 1000
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000000 {
+			http.Error(w, "query parameter count: must be between 1 and 1000000", 400)
+			return
+		}
+		count = n
+	}
+
+	opts, err := redis.ParseURL(os.Getenv("BM_PLUGIN_REDIS_URL"))
+	if err != nil {
+		http.Error(w, "envvar BM_PLUGIN_REDIS_URL: "+err.Error(), 500)
+		return
+	}
+
+	client := redis.NewClient(opts)
+	defer client.Close()
+
+	w.Header().Add("content-type", "application/json")
+
+	res, err := benchmarkRedisLatency(r.Context(), client, count)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(500)
+		return
+	}
+
+	if b, err := json.Marshal(res); err != nil {
+		w.WriteHeader(500)
+		return
+	} else {
+		w.Write(b)
+		return
+	}
+}
+
+func benchmarkRedisLatency(ctx context.Context, client *redis.Client, count int) (*Result, error) {
+	// Establish the connection before timing anything.
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("ping redis: %w", err)
+	}
+
+	samples := make([]time.Duration, 0, count)
+	for range count {
+		start := time.Now()
+		if err := client.Ping(ctx).Err(); err != nil {
+			return nil, fmt.Errorf("ping redis: %w", err)
+		}
+		samples = append(samples, time.Since(start))
+	}
+
+	slices.Sort(samples)
+
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+	return &Result{
+		Count: count,
+		MinMs: ms(samples[0]),
+		P50Ms: ms(samples[len(samples)*50/100]),
+		P99Ms: ms(samples[len(samples)*99/100]),
+		MaxMs: ms(samples[len(samples)-1]),
+	}, nil
+}
+
+// main is never called; it only lets the package build outside plugin mode.
+func main() {}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+func benchExecSpawn(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		ExecSpawnResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	command := r.URL.Query().Get("command")
+	if command == "" {
+		if len(allowedCommands) == 0 {
+			http.Error(w, "query parameter command: no commands are allowed (BM_ALLOWED_COMMANDS)", 400)
+			return
+		}
+		command = allowedCommands[0]
+	}
+
+	// Only the configured commands may be run, so the endpoint cannot be used
+	// to run anything else on the host.
+	if !slices.Contains(allowedCommands, command) {
+		http.Error(w, fmt.Sprintf("query parameter command: %q is not an allowed command", command), 403)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkExecSpawn(command, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.ExecSpawnResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type ExecSpawnResult struct {
+	Command string
+	Count   int
+	Latency LatencyStats
+}
+
+var allowedCommands []string
+
+// benchmarkExecSpawn runs command count times with exec.Command and times
+// each run from start to exit. The command line is split on spaces, without
+// a shell.
+func benchmarkExecSpawn(command string, count int) (*ExecSpawnResult, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	samples := make([]time.Duration, 0, count)
+
+	for range count {
+		start := time.Now()
+		if err := exec.Command(args[0], args[1:]...).Run(); err != nil {
+			return nil, fmt.Errorf("run %s: %w", command, err)
+		}
+		samples = append(samples, time.Since(start))
+	}
+
+	return &ExecSpawnResult{
+		Command: command,
+		Count:   count,
+		Latency: latencyStats(samples),
+	}, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+func benchFDLimit(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		FDLimitResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	res, err := benchmarkFDLimit()
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.FDLimitResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type FDLimitResult struct {
+	SoftLimit               uint64
+	HardLimit               uint64
+	MaxSimultaneouslyOpened int
+	OpenRatePerSec          float64
+}
+
+// fdLimitFraction is how much of the soft limit benchmarkFDLimit uses. The
+// rest is left for the server's own sockets and files.
+const fdLimitFraction = 0.9
+
+// benchmarkFDLimit opens os.DevNull until fdLimitFraction of RLIMIT_NOFILE's
+// soft limit is in use, then closes everything again. The Go runtime raises
+// the soft limit to the hard limit at startup, so that is usually what is
+// measured against.
+func benchmarkFDLimit() (*FDLimitResult, error) {
+	var rlim syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlim); err != nil {
+		return nil, fmt.Errorf("get RLIMIT_NOFILE: %w", err)
+	}
+
+	target := int(float64(rlim.Cur) * fdLimitFraction)
+
+	files := make([]*os.File, 0, target)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	start := time.Now()
+
+	for range target {
+		f, err := os.Open(os.DevNull)
+		if err != nil {
+			return nil, fmt.Errorf("open file %d of %d (soft limit %d, hard limit %d): %w", len(files)+1, target, rlim.Cur, rlim.Max, err)
+		}
+		files = append(files, f)
+	}
+
+	since := time.Since(start)
+
+	return &FDLimitResult{
+		SoftLimit:               rlim.Cur,
+		HardLimit:               rlim.Max,
+		MaxSimultaneouslyOpened: len(files),
+		OpenRatePerSec:          float64(len(files)) / since.Seconds(),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func benchFileLock(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		FileLockResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	workers, err := queryInt(r, "workers", 8, 1, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkFileLock(dir, workers, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.FileLockResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type FileLockResult struct {
+	Workers            int
+	Acquisitions       int
+	Seconds            float32
+	AcquisitionsPerSec float64
+	MeanWaitMs         float64
+}
+
+// benchmarkFileLock has workers goroutines each take an exclusive flock on the
+// same file count times. Every worker opens the file itself, so the locks
+// contend exactly as they would between processes. While holding the lock a
+// worker increments a counter stored in the file.
+func benchmarkFileLock(dir string, workers int, count int) (*FileLockResult, error) {
+	f, err := createTemp(dir, "file_lock_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	name := f.Name()
+	defer removeTemp(name)
+
+	_, err = f.Write(make([]byte, 8))
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("write lock file: %w", err)
+	}
+
+	waits := make([]time.Duration, workers)
+
+	var g errgroup.Group
+
+	start := time.Now()
+
+	for i := range workers {
+		g.Go(func() error {
+			f, err := os.OpenFile(name, os.O_RDWR, 0)
+			if err != nil {
+				return fmt.Errorf("open lock file: %w", err)
+			}
+			defer f.Close()
+
+			buf := make([]byte, 8)
+
+			for range count {
+				lockStart := time.Now()
+				if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+					return fmt.Errorf("flock: %w", err)
+				}
+				waits[i] += time.Since(lockStart)
+
+				_, rwErr := f.ReadAt(buf, 0)
+				if rwErr == nil {
+					binary.LittleEndian.PutUint64(buf, binary.LittleEndian.Uint64(buf)+1)
+					_, rwErr = f.WriteAt(buf, 0)
+				}
+
+				if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
+					return fmt.Errorf("unlock: %w", err)
+				}
+				if rwErr != nil {
+					return fmt.Errorf("read-modify-write lock file: %w", rwErr)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	since := time.Since(start)
+
+	var totalWait time.Duration
+	for _, wait := range waits {
+		totalWait += wait
+	}
+
+	acquisitions := workers * count
+
+	return &FileLockResult{
+		Workers:            workers,
+		Acquisitions:       acquisitions,
+		Seconds:            float32(since) / float32(time.Second),
+		AcquisitionsPerSec: float64(acquisitions) / since.Seconds(),
+		MeanWaitMs:         durationMs(totalWait) / float64(acquisitions),
+	}, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func benchFileServer(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		FileServerResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	files, err := queryInt(r, "files", 100, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeKB, err := queryInt(r, "size_kb", 64, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	requests, err := queryInt(r, "requests", 10000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkFileServer(dir, files, sizeKB*1024, requests)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.FileServerResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type FileServerResult struct {
+	Files          int
+	FileSize       int
+	Requests       int
+	Concurrency    int
+	ThroughputMBps float64
+	Latency        LatencyStats
+}
+
+// fileServerConcurrency is how many goroutines share the requests.
+const fileServerConcurrency = 16
+
+// benchmarkFileServer writes fileCount files of fileSize bytes to a temp dir
+// in dir, serves it with http.FileServer on an httptest.Server and fetches
+// the files round robin with requests GET requests from
+// fileServerConcurrency goroutines.
+func benchmarkFileServer(dir string, fileCount int, fileSize int, requests int) (*FileServerResult, error) {
+	tmp, err := mkdirTemp(dir, "file_server_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(tmp)
+
+	buf := make([]byte, fileSize)
+	if err := fillContent(buf); err != nil {
+		return nil, err
+	}
+	for i := range fileCount {
+		if err := os.WriteFile(filepath.Join(tmp, strconv.Itoa(i)), buf, 0o644); err != nil {
+			return nil, fmt.Errorf("write file: %w", err)
+		}
+	}
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(tmp)))
+	defer srv.Close()
+
+	client := srv.Client()
+	client.Transport.(*http.Transport).MaxIdleConnsPerHost = fileServerConcurrency
+	defer client.CloseIdleConnections()
+
+	var next, bytes atomic.Int64
+	samples := make([][]time.Duration, fileServerConcurrency)
+
+	var g errgroup.Group
+
+	start := time.Now()
+
+	for i := range fileServerConcurrency {
+		g.Go(func() error {
+			for n := next.Add(1); n <= int64(requests); n = next.Add(1) {
+				sent := time.Now()
+
+				resp, err := client.Get(srv.URL + "/" + strconv.FormatInt(n%int64(fileCount), 10))
+				if err != nil {
+					return fmt.Errorf("get: %w", err)
+				}
+				read, err := io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					return fmt.Errorf("read response: %w", err)
+				}
+				if resp.StatusCode != 200 {
+					return fmt.Errorf("get: status %s", resp.Status)
+				}
+
+				samples[i] = append(samples[i], time.Since(sent))
+				bytes.Add(read)
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	elapsed := time.Since(start)
+
+	return &FileServerResult{
+		Files:          fileCount,
+		FileSize:       fileSize,
+		Requests:       requests,
+		Concurrency:    fileServerConcurrency,
+		ThroughputMBps: float64(bytes.Load()) / (1 << 20) / elapsed.Seconds(),
+		Latency:        latencyStats(slices.Concat(samples...)),
+	}, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+type FilesystemInfo struct {
+	FSType       string
+	MountOptions string
+	Device       string
+	MountPoint   string
+	IOScheduler  string
+}
+
+// DiskMeta describes the filesystems behind both benchmark directories. It
+// is embedded in the responses of the benchmarks that touch the disk.
+type DiskMeta struct {
+	EphemeralFS  FilesystemInfo
+	PersistentFS FilesystemInfo
+}
+
+func diskMeta() DiskMeta {
+	return DiskMeta{
+		EphemeralFS:  filesystemInfo(ephemeralDir),
+		PersistentFS: filesystemInfo(persistentDir),
+	}
+}
+
+// filesystemInfo is detectFilesystemType with the I/O scheduler of the device
+// filled in.
+func filesystemInfo(path string) FilesystemInfo {
+	info := detectFilesystemType(path)
+	info.IOScheduler = detectIOScheduler(info.Device)
+	return info
+}
+
+// detectFilesystemType finds the /proc/mounts entry for the filesystem that
+// path lives on: the deepest mount point above path whose device number
+// matches path's. The result is empty when path or /proc/mounts cannot be
+// read.
+func detectFilesystemType(path string) FilesystemInfo {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return FilesystemInfo{}
+	}
+
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return FilesystemInfo{}
+	}
+
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return FilesystemInfo{}
+	}
+	defer f.Close()
+
+	var best FilesystemInfo
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		mountPoint := unescapeMountField(fields[1])
+		if !isPathPrefix(mountPoint, path) || len(mountPoint) < len(best.MountPoint) {
+			continue
+		}
+
+		var mst syscall.Stat_t
+		if err := syscall.Stat(mountPoint, &mst); err != nil || mst.Dev != st.Dev {
+			continue
+		}
+
+		best = FilesystemInfo{
+			FSType:       fields[2],
+			MountOptions: fields[3],
+			Device:       unescapeMountField(fields[0]),
+			MountPoint:   mountPoint,
+		}
+	}
+
+	return best
+}
+
+func isPathPrefix(prefix, path string) bool {
+	if prefix == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// unescapeMountField undoes the octal escaping /proc/mounts applies to
+// spaces, tabs, newlines and backslashes.
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func benchFlockVsMutex(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		FlockVsMutexResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkFlockVsMutex(dir, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.FlockVsMutexResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// FlockVsMutexResult is the uncontended cost of a lock and unlock cycle.
+// FlockOverheadFactor is FlockNsPerOp divided by MutexNsPerOp.
+type FlockVsMutexResult struct {
+	Count               int
+	FlockNsPerOp        float64
+	MutexNsPerOp        float64
+	FlockOverheadFactor float64
+}
+
+// benchmarkFlockVsMutex takes and releases an exclusive flock on a temp file
+// in dir count times, then does the same with a sync.Mutex. Unlike
+// benchmarkFileLock there is no contention, so this is the fixed cost of the
+// two system calls against a few atomic instructions.
+func benchmarkFlockVsMutex(dir string, count int) (*FlockVsMutexResult, error) {
+	f, err := createTemp(dir, "flock_vs_mutex_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	fd := int(f.Fd())
+
+	start := time.Now()
+
+	for range count {
+		if err := syscall.Flock(fd, syscall.LOCK_EX); err != nil {
+			return nil, fmt.Errorf("flock: %w", err)
+		}
+		if err := syscall.Flock(fd, syscall.LOCK_UN); err != nil {
+			return nil, fmt.Errorf("unlock: %w", err)
+		}
+	}
+
+	flock := time.Since(start)
+
+	var mu sync.Mutex
+
+	start = time.Now()
+
+	for range count {
+		mu.Lock()
+		mu.Unlock()
+	}
+
+	mutex := time.Since(start)
+
+	return &FlockVsMutexResult{
+		Count:               count,
+		FlockNsPerOp:        float64(flock.Nanoseconds()) / float64(count),
+		MutexNsPerOp:        float64(mutex.Nanoseconds()) / float64(count),
+		FlockOverheadFactor: flock.Seconds() / mutex.Seconds(),
+	}, nil
+}
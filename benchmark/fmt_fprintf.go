@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func benchFmtFprintf(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		FmtFprintfResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	count, err := queryInt(r, "count", 1000000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.FmtFprintfResult = *benchmarkFmtFprintf(count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// FmtFprintfResult holds one run per method, keyed by name. Every method
+// produces the same line, see benchmarkFmtFprintf.
+type FmtFprintfResult struct {
+	Count   int
+	Methods map[string]StringBuildRun
+}
+
+// benchmarkFmtFprintf formats a line with a string, an int and a float count
+// times with fmt.Fprintf to io.Discard, fmt.Fprintf to a new strings.Builder
+// and concatenation of strconv results with +. Allocations are counted the
+// same way as in benchmarkStringBuilding.
+func benchmarkFmtFprintf(count int) *FmtFprintfResult {
+	name, n, f := "requests", 1234, 56.78
+
+	methods := map[string]func() string{
+		"Fprintf/io.Discard": func() string {
+			fmt.Fprintf(io.Discard, "%s: %d in %f s\n", name, n, f)
+			return ""
+		},
+		"Fprintf/strings.Builder": func() string {
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s: %d in %f s\n", name, n, f)
+			return b.String()
+		},
+		"+": func() string {
+			return name + ": " + strconv.Itoa(n) + " in " + strconv.FormatFloat(f, 'f', 6, 64) + " s\n"
+		},
+	}
+
+	res := &FmtFprintfResult{Count: count, Methods: map[string]StringBuildRun{}}
+
+	for method, format := range methods {
+		runtime.GC()
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+
+		var s string
+		start := time.Now()
+		for range count {
+			s = format()
+		}
+		since := time.Since(start)
+
+		runtime.ReadMemStats(&after)
+		keep(s)
+
+		res.Methods[method] = StringBuildRun{
+			Ops:         count,
+			NsPerOp:     float64(since.Nanoseconds()) / float64(count),
+			AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(count),
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+func benchFullSystem(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		SuiteResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	res, err := fullSystemSuite().Run(r.Context())
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.SuiteResult = res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// fullSystemSuite is one benchmark of each category, with parameters small
+// enough for the whole suite to finish in under a minute. The disks run
+// quickSizeClasses, like the quick runs of /metrics/summary.
+func fullSystemSuite() *BenchmarkSuite {
+	var suite BenchmarkSuite
+
+	quick := DiskOptions{Classes: quickSizeClasses(), SrcFileCount: srcFileCount}
+
+	suite.Add("ephemeral-disk", func(ctx context.Context) (any, error) {
+		return benchmarkRWDisk(ctx, ephemeralDir, quick)
+	})
+	suite.Add("persistent-disk", func(ctx context.Context) (any, error) {
+		return benchmarkRWDisk(ctx, persistentDir, quick)
+	})
+	suite.Add("random-read", func(ctx context.Context) (any, error) {
+		return benchmarkRandomRead(ephemeralDir, 64*1024*1024, 4096, 1000)
+	})
+	suite.Add("strconv", func(ctx context.Context) (any, error) {
+		return benchmarkStrconv(1000000)
+	})
+	suite.Add("allocator", func(ctx context.Context) (any, error) {
+		return benchmarkAllocator(64, 1000000), nil
+	})
+	suite.Add("net-pipe", func(ctx context.Context) (any, error) {
+		return benchmarkNetPipe(1024, 10000)
+	})
+	suite.Add("network", func(ctx context.Context) (any, error) {
+		return benchmarkNetwork(networkTargets, networkTargetsV6, 5), nil
+	})
+
+	return &suite
+}
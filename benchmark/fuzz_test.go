@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func FuzzSizeRange(f *testing.F) {
+	f.Add(1, 2)
+	f.Add(128, 1024)
+	f.Add(128*1024*1024, 512*1024*1024)
+	f.Add(0, 10)
+	f.Add(10, 10)
+	f.Add(20, 10)
+	f.Add(-1, 1)
+	f.Add(1, maxSizeRangeBytes+1)
+	f.Add(math.MinInt, math.MaxInt)
+	f.Add(math.MaxInt, math.MinInt)
+	f.Add(math.MaxInt-1, math.MaxInt)
+
+	f.Fuzz(func(t *testing.T, min, max int) {
+		sr, err := NewSizeRange(min, max)
+
+		valid := min >= 1 && max > min && max <= maxSizeRangeBytes
+		if valid && err != nil {
+			t.Fatalf("NewSizeRange(%d, %d) = %v, want no error", min, max, err)
+		}
+		if !valid && err == nil {
+			t.Fatalf("NewSizeRange(%d, %d) accepted an invalid range", min, max)
+		}
+		if err == nil && (sr.min != min || sr.max != max) {
+			t.Fatalf("NewSizeRange(%d, %d) = %+v", min, max, sr)
+		}
+	})
+}
+
+// FuzzQueryInt feeds arbitrary src_count values through queryInt with the
+// bounds the disk endpoints use.
+func FuzzQueryInt(f *testing.F) {
+	for _, v := range []string{"", "1", "10", "15", "16", "1000", "0", "-1", "abc", "1e3", "0x10", " 5", "+5", "9223372036854775808"} {
+		f.Add(v)
+	}
+
+	f.Fuzz(func(t *testing.T, v string) {
+		r := httptest.NewRequest("GET", "/?src_count="+url.QueryEscape(v), nil)
+
+		n, err := queryInt(r, "src_count", srcFileCount, 1, maxSrcFileCount)
+		if err != nil {
+			return
+		}
+		if v == "" && n != srcFileCount {
+			t.Fatalf("absent value gave %d, want the default", n)
+		}
+		if n < 1 || n > maxSrcFileCount {
+			t.Fatalf("queryInt accepted %q as %d, outside [1, %d]", v, n, maxSrcFileCount)
+		}
+	})
+}
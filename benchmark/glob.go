@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func benchGlob(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		GlobResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	files, err := queryInt(r, "files", 10000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	pattern := r.URL.Query().Get("pattern")
+	if pattern == "" {
+		pattern = "*.go"
+	}
+	if strings.ContainsRune(pattern, filepath.Separator) {
+		http.Error(w, "query parameter pattern: must not contain a path separator", 400)
+		return
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		http.Error(w, fmt.Sprintf("query parameter pattern: %v", err), 400)
+		return
+	}
+
+	res, err := benchmarkGlob(dir, files, pattern)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.GlobResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type GlobResult struct {
+	Files   int
+	Pattern string
+	Matches int
+	Latency LatencyStats
+}
+
+// globCalls is how many times the pattern is matched against the directory.
+const globCalls = 100
+
+// globExtensions are spread evenly over the created files, so the default
+// pattern matches about a quarter of them.
+var globExtensions = []string{".go", ".txt", ".json", ".log"}
+
+// benchmarkGlob creates fileCount empty files with random hex names in a
+// temp dir in dir and calls filepath.Glob with pattern, relative to that
+// dir, globCalls times.
+func benchmarkGlob(dir string, fileCount int, pattern string) (*GlobResult, error) {
+	tmp, err := mkdirTemp(dir, "glob_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(tmp)
+
+	for i := range fileCount {
+		name := fmt.Sprintf("%016x%s", rand.Uint64(), globExtensions[i%len(globExtensions)])
+		f, err := os.Create(filepath.Join(tmp, name))
+		if err != nil {
+			return nil, fmt.Errorf("create file: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return nil, fmt.Errorf("close file: %w", err)
+		}
+	}
+
+	res := &GlobResult{Files: fileCount, Pattern: pattern}
+	samples := make([]time.Duration, globCalls)
+
+	for i := range samples {
+		start := time.Now()
+		matches, err := filepath.Glob(filepath.Join(tmp, pattern))
+		samples[i] = time.Since(start)
+		if err != nil {
+			return nil, fmt.Errorf("glob: %w", err)
+		}
+		res.Matches = len(matches)
+	}
+
+	res.Latency = latencyStats(samples)
+
+	return res, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+func benchmarkHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	disk := q.Get("disk")
+	if disk != "" && disk != "ephemeral" && disk != "persistent" {
+		http.Error(w, fmt.Sprintf("query parameter disk: unknown disk %q", disk), 400)
+		return
+	}
+
+	metric := q.Get("metric")
+	if metric == "" {
+		http.Error(w, "query parameter metric: required", 400)
+		return
+	}
+
+	var from, to time.Time
+	if v := q.Get("from"); v != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "query parameter from: "+err.Error(), 400)
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		var err error
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "query parameter to: "+err.Error(), 400)
+			return
+		}
+	}
+
+	results, err := resultStore.Read()
+	if err != nil {
+		fmt.Println(requestIDFromContext(r.Context()), err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	points := []TimeSeriesPoint{}
+
+	for _, res := range results {
+		if disk != "" && res.Disk != disk {
+			continue
+		}
+		if !from.IsZero() && res.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && res.Timestamp.After(to) {
+			continue
+		}
+
+		value, ok, err := metricValue(res.Result, metric)
+		if err != nil {
+			http.Error(w, "query parameter metric: "+err.Error(), 400)
+			return
+		}
+		if !ok {
+			continue
+		}
+
+		points = append(points, TimeSeriesPoint{res.Timestamp, value})
+	}
+
+	writeCachedJSON(w, r, points)
+}
+
+type TimeSeriesPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// metricValue resolves a dot-separated path such as "TinyRW.Seconds" against
+// v. Each component names either a field or a method without arguments. ok is
+// false when the path runs through a nil pointer, which is the case for size
+// classes that did not run.
+func metricValue(v any, path string) (value float64, ok bool, err error) {
+	cur := reflect.ValueOf(v)
+
+	for _, name := range strings.Split(path, ".") {
+		for cur.Kind() == reflect.Pointer {
+			if cur.IsNil() {
+				return 0, false, nil
+			}
+			cur = cur.Elem()
+		}
+		if cur.Kind() != reflect.Struct {
+			return 0, false, fmt.Errorf("%s: not a struct", name)
+		}
+
+		// Copy into a pointer so methods with pointer receivers are found too.
+		ptr := reflect.New(cur.Type())
+		ptr.Elem().Set(cur)
+
+		if promotedThroughNil(cur, name) {
+			return 0, false, nil
+		}
+
+		if m := ptr.MethodByName(name); m.IsValid() {
+			if m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+				return 0, false, fmt.Errorf("%s: method must take no arguments and return one value", name)
+			}
+			cur = m.Call(nil)[0]
+			continue
+		}
+
+		field, ok := cur.Type().FieldByName(name)
+		if !ok || !field.IsExported() {
+			return 0, false, fmt.Errorf("%s: no such field", name)
+		}
+
+		// Fails when the field is promoted through a nil embedded pointer.
+		next, err := cur.FieldByIndexErr(field.Index)
+		if err != nil {
+			return 0, false, nil
+		}
+		cur = next
+	}
+
+	switch cur.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(cur.Int()), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(cur.Uint()), true, nil
+	case reflect.Float32, reflect.Float64:
+		return cur.Float(), true, nil
+	default:
+		return 0, false, fmt.Errorf("%s: not a number", path)
+	}
+}
+
+// promotedThroughNil reports whether the method name of the struct v is
+// promoted from an embedded pointer that is nil, such as IOPS on a timed out
+// DiskResultWithMeta.
+func promotedThroughNil(v reflect.Value, name string) bool {
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.Anonymous || field.Type.Kind() != reflect.Pointer || !v.Field(i).IsNil() {
+			continue
+		}
+		if _, ok := field.Type.MethodByName(name); ok {
+			return true
+		}
+	}
+	return false
+}
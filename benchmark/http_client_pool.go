@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func benchHTTPClientPool(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		HTTPClientPoolResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	concurrency, err := queryInt(r, "concurrency", 16, 1, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	requests, err := queryInt(r, "requests", 10000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkHTTPClientPool(concurrency, requests)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.HTTPClientPoolResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type HTTPClientPoolResult struct {
+	Concurrency    int
+	Requests       int
+	RequestsPerSec float64
+	Latency        LatencyStats
+
+	// NewConnections is how many TCP connections the server accepted. With
+	// working reuse it stays close to Concurrency.
+	NewConnections int64
+}
+
+// benchmarkHTTPClientPool starts an HTTP server on the loopback interface and
+// sends it requests GET requests from concurrency goroutines sharing one
+// http.Client, whose idle pool is large enough to keep a connection per
+// goroutine.
+func benchmarkHTTPClientPool(concurrency int, requests int) (*HTTPClientPoolResult, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	var conns atomic.Int64
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}),
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				conns.Add(1)
+			}
+		},
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:        concurrency,
+			MaxIdleConnsPerHost: concurrency,
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	url := "http://" + ln.Addr().String() + "/"
+
+	var next atomic.Int64
+	samples := make([][]time.Duration, concurrency)
+
+	var g errgroup.Group
+
+	start := time.Now()
+
+	for i := range concurrency {
+		g.Go(func() error {
+			for next.Add(1) <= int64(requests) {
+				sent := time.Now()
+
+				resp, err := client.Get(url)
+				if err != nil {
+					return fmt.Errorf("get: %w", err)
+				}
+				// The body must be drained for the connection to be reused.
+				_, err = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					return fmt.Errorf("read response: %w", err)
+				}
+
+				samples[i] = append(samples[i], time.Since(sent))
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	elapsed := time.Since(start)
+
+	return &HTTPClientPoolResult{
+		Concurrency:    concurrency,
+		Requests:       requests,
+		RequestsPerSec: float64(requests) / elapsed.Seconds(),
+		Latency:        latencyStats(slices.Concat(samples...)),
+		NewConnections: conns.Load(),
+	}, nil
+}
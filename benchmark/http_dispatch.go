@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"time"
+)
+
+func benchHTTPDispatch(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		HTTPDispatchResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	routes, err := queryInt(r, "routes", 1000, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	requests, err := queryInt(r, "requests", 100000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkHTTPDispatch(routes, requests)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.HTTPDispatchResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// HTTPDispatchResult has one run per route table size, smallest first.
+type HTTPDispatchResult struct {
+	Requests int
+	Runs     []DispatchRun
+}
+
+type DispatchRun struct {
+	Routes       int
+	NsPerRequest float64
+}
+
+// httpDispatchSizes are the route table sizes always measured, next to the
+// one asked for.
+var httpDispatchSizes = []int{10, 100, 1000}
+
+// benchmarkHTTPDispatch measures how long http.ServeMux takes to pick the
+// handler for a request, for route tables of each of httpDispatchSizes and of
+// routes routes. Every route has a path wildcard, like a REST API, and the
+// requests are spread over the routes at random. The handler does nothing and
+// the requests go straight to ServeHTTP with an httptest.ResponseRecorder.
+func benchmarkHTTPDispatch(routes int, requests int) (*HTTPDispatchResult, error) {
+	sizes := slices.Clone(httpDispatchSizes)
+	if !slices.Contains(sizes, routes) {
+		sizes = append(sizes, routes)
+		slices.Sort(sizes)
+	}
+
+	res := &HTTPDispatchResult{Requests: requests}
+
+	for _, size := range sizes {
+		mux := http.NewServeMux()
+		for i := range size {
+			mux.HandleFunc(fmt.Sprintf("GET /api/resource%d/{id}", i), func(w http.ResponseWriter, r *http.Request) {})
+		}
+
+		reqs := make([]*http.Request, min(size, 1000))
+		for i := range reqs {
+			reqs[i] = httptest.NewRequest("GET", fmt.Sprintf("/api/resource%d/42", rand.IntN(size)), nil)
+		}
+
+		rec := httptest.NewRecorder()
+
+		start := time.Now()
+
+		for i := range requests {
+			mux.ServeHTTP(rec, reqs[i%len(reqs)])
+		}
+
+		since := time.Since(start)
+
+		if rec.Code != 200 {
+			return nil, fmt.Errorf("dispatch with %d routes: status %d", size, rec.Code)
+		}
+
+		res.Runs = append(res.Runs, DispatchRun{
+			Routes:       size,
+			NsPerRequest: float64(since.Nanoseconds()) / float64(requests),
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func benchHTTPParsing(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		HTTPParsingResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	headers, err := queryInt(r, "headers", 20, 0, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	bodyKB, err := queryInt(r, "body_kb", 1, 0, 16*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkHTTPParsing(headers, bodyKB*1024, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.HTTPParsingResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// HTTPParsingResult compares serving a request that has to be parsed from
+// the wire format with serving an already parsed one. ParsingNsPerOp is the
+// difference.
+type HTTPParsingResult struct {
+	Headers         int
+	BodySize        int
+	Count           int
+	ParsedNsPerOp   float64
+	BaselineNsPerOp float64
+	ParsingNsPerOp  float64
+}
+
+// benchmarkHTTPParsing builds a POST request with headerCount custom headers
+// and a body of bodySize bytes in HTTP/1.1 wire format. It then count times
+// parses it with http.ReadRequest and serves it to a handler that reads the
+// body, with an httptest.ResponseRecorder. The baseline serves a request
+// built with httptest.NewRequest the same way, so only the parsing differs.
+func benchmarkHTTPParsing(headerCount int, bodySize int, count int) (*HTTPParsingResult, error) {
+	body := make([]byte, bodySize)
+	if err := fillContent(body); err != nil {
+		return nil, err
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/items?page=1&sort=desc", nil)
+	req.Header.Set("content-type", "application/octet-stream")
+	for i := range headerCount {
+		req.Header.Set(fmt.Sprintf("X-Custom-Header-%d", i), fmt.Sprintf("value-%d-abcdefghijklmnopqrstuvwxyz", i))
+	}
+
+	var raw bytes.Buffer
+	fmt.Fprintf(&raw, "POST %s HTTP/1.1\r\nHost: %s\r\nContent-Length: %d\r\n", req.URL.RequestURI(), req.Host, bodySize)
+	if err := req.Header.Write(&raw); err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+	raw.WriteString("\r\n")
+	raw.Write(body)
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.Copy(io.Discard, r.Body)
+		return err
+	}
+
+	rd := bytes.NewReader(raw.Bytes())
+	br := bufio.NewReader(rd)
+
+	start := time.Now()
+
+	for range count {
+		rd.Reset(raw.Bytes())
+		br.Reset(rd)
+
+		r, err := http.ReadRequest(br)
+		if err != nil {
+			return nil, fmt.Errorf("parse request: %w", err)
+		}
+		if err := handler(httptest.NewRecorder(), r); err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+	}
+
+	parsed := time.Since(start)
+
+	bodyReader := bytes.NewReader(body)
+	req.Body = io.NopCloser(bodyReader)
+
+	start = time.Now()
+
+	for range count {
+		bodyReader.Reset(body)
+		if err := handler(httptest.NewRecorder(), req); err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+	}
+
+	baseline := time.Since(start)
+
+	res := &HTTPParsingResult{
+		Headers:         headerCount,
+		BodySize:        bodySize,
+		Count:           count,
+		ParsedNsPerOp:   float64(parsed.Nanoseconds()) / float64(count),
+		BaselineNsPerOp: float64(baseline.Nanoseconds()) / float64(count),
+	}
+	res.ParsingNsPerOp = res.ParsedNsPerOp - res.BaselineNsPerOp
+
+	return res, nil
+}
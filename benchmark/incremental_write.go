@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+func benchIncrementalWrite(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		IncrementalWriteResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeMB, err := queryInt(r, "size_mb", 1024, 10, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkIncrementalWrite(dir, sizeMB)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.IncrementalWriteResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type IncrementalWriteResult struct {
+	TargetSizeMB  int
+	Seconds       float32
+	GrowthProfile []GrowthSample
+}
+
+// GrowthSample is the write throughput of the chunks written since the
+// previous sample, taken once the file reached PercentFull of its target size.
+type GrowthSample struct {
+	PercentFull    float64
+	ThroughputMBps float64
+}
+
+var growthCheckpoints = []float64{10, 25, 50, 75, 100}
+
+// benchmarkIncrementalWrite grows a single file to targetSizeMB in 1 MiB
+// chunks. The file is synced at every checkpoint so each sample includes the
+// cost of getting its chunks onto the disk.
+func benchmarkIncrementalWrite(dir string, targetSizeMB int) (*IncrementalWriteResult, error) {
+	chunk := make([]byte, 1024*1024)
+	if err := fillContent(chunk); err != nil {
+		return nil, err
+	}
+
+	f, err := createTemp(dir, "incremental_write_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	res := &IncrementalWriteResult{TargetSizeMB: targetSizeMB}
+
+	start := time.Now()
+	sampleStart := start
+	written := 0
+
+	for _, pct := range growthCheckpoints {
+		until := int(math.Ceil(float64(targetSizeMB) * pct / 100))
+		chunks := until - written
+
+		for ; written < until; written++ {
+			if _, err := f.Write(chunk); err != nil {
+				return nil, fmt.Errorf("write to temp file: %w", err)
+			}
+		}
+
+		if err := f.Sync(); err != nil {
+			return nil, fmt.Errorf("sync temp file: %w", err)
+		}
+
+		now := time.Now()
+		res.GrowthProfile = append(res.GrowthProfile, GrowthSample{
+			PercentFull:    pct,
+			ThroughputMBps: float64(chunks) / now.Sub(sampleStart).Seconds(),
+		})
+		sampleStart = now
+	}
+
+	res.Seconds = float32(time.Since(start)) / float32(time.Second)
+
+	return res, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+)
+
+// InfluxDBExporter writes disk benchmark results to InfluxDB as points of the
+// disk_benchmark measurement, with the fields of the JSON result prefixed by
+// the size class name.
+type InfluxDBExporter struct {
+	client   influxdb2.Client
+	writeAPI api.WriteAPIBlocking
+}
+
+// newInfluxDBExporter returns nil unless cfg.URL is set.
+func newInfluxDBExporter(cfg InfluxDBConfig) *InfluxDBExporter {
+	if cfg.URL == "" {
+		return nil
+	}
+
+	client := influxdb2.NewClient(cfg.URL, cfg.Token)
+
+	return &InfluxDBExporter{
+		client:   client,
+		writeAPI: client.WriteAPIBlocking(cfg.Org, cfg.Bucket),
+	}
+}
+
+// Export writes res in the background so the HTTP response is not held up by
+// InfluxDB. It is a no-op on a nil exporter.
+func (e *InfluxDBExporter) Export(diskType string, res DiskBenchmarkResult) {
+	if e == nil {
+		return
+	}
+
+	fields := map[string]any{}
+	for _, class := range defaultSizeClasses() {
+		rw := res.get(class.Name)
+		if rw == nil {
+			continue
+		}
+		fields[class.Name+"_seconds"] = rw.Seconds
+		fields[class.Name+"_count"] = rw.Count
+		fields[class.Name+"_bytes"] = rw.Bytes
+		fields[class.Name+"_throughput_mbps"] = rw.ThroughputMBps()
+		fields[class.Name+"_iops"] = rw.IOPS()
+		fields[class.Name+"_retries"] = rw.Retries
+		fields[class.Name+"_final_cv"] = rw.FinalCV
+		fields[class.Name+"_verified_count"] = rw.VerifiedCount
+	}
+
+	tags := map[string]string{
+		"disk_type":     diskType,
+		"instance_type": cloudMeta.InstanceType,
+		"region":        cloudMeta.Region,
+	}
+
+	point := influxdb2.NewPoint("disk_benchmark", tags, fields, time.Now())
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := e.writeAPI.WritePoint(ctx, point); err != nil {
+			fmt.Println(fmt.Errorf("influxdb export: %w", err))
+		}
+	}()
+}
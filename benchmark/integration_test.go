@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testServerURL is the base URL of the server TestMain starts.
+var testServerURL string
+
+func TestMain(m *testing.M) {
+	os.Exit(runWithTestServer(m))
+}
+
+// runWithTestServer starts the server main would run, with both benchmark
+// directories in fresh temp dirs, on a random loopback port.
+func runWithTestServer(m *testing.M) int {
+	for _, env := range []string{"BM_EPHEMERAL_DIR", "BM_PERSISTENT_DIR"} {
+		dir, err := os.MkdirTemp("", "benchmark_test_*")
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		os.Setenv(env, dir)
+	}
+	os.Setenv("BM_CONFIG_FILE", "")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if err := applyConfig(cfg); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	srv, err := newServer(cfg)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	testServerURL = "http://" + ln.Addr().String()
+
+	return m.Run()
+}
+
+// smallClassesBody is testClassesBody without the huge class, which only runs
+// outside short mode.
+const smallClassesBody = `{"classes": {
+	"tiny":   {"count": 3, "size_range": {"min": 10, "max": 20}},
+	"small":  {"count": 3, "size_range": {"min": 100, "max": 200}},
+	"medium": {"count": 3, "size_range": {"min": 1000, "max": 2000}},
+	"large":  {"count": 3, "size_range": {"min": 10000, "max": 20000}}
+}}`
+
+// TestEndpoints calls every endpoint of the running server in order: the disk
+// benchmarks come first so the endpoints that read stored results have
+// something to show. Cases marked huge write real huge-class files and are
+// skipped with -short.
+func TestEndpoints(t *testing.T) {
+	// Earlier tests may have applied their own config; go back to the one
+	// TestMain started the server with.
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	const classesQuery = "?src_count=2&classes=tiny,small,medium,large"
+
+	tests := []struct {
+		method      string
+		path        string
+		body        string
+		status      int
+		contentType string
+		huge        bool
+	}{
+		{"POST", "/ephemeral-disk" + classesQuery, smallClassesBody, 200, "application/json", false},
+		{"POST", "/persistent-disk" + classesQuery, smallClassesBody, 200, "application/json", false},
+		{"POST", "/ephemeral-disk?src_count=2&classes=huge", `{"classes": {"huge": {"count": 1}}}`, 200, "application/json", true},
+		{"POST", "/persistent-disk?src_count=2&classes=huge", `{"classes": {"huge": {"count": 1}}}`, 200, "application/json", true},
+		{"POST", "/ephemeral-disk", `{"classes": {"giant": {}}}`, 422, "application/json", false},
+		{"POST", "/ephemeral-disk", `{"classes": {"tiny": {"count": 9223372036854775807}}}`, 422, "application/json", false},
+		{"POST", "/ephemeral-disk", `{"classes": {"tiny": {"size_range": {"min": 100, "max": 110}}}}`, 422, "application/json", false},
+		{"GET", "/incremental-write?size_mb=10", "", 200, "application/json", false},
+		{"GET", "/read-after-write?count=10", "", 200, "application/json", false},
+		{"GET", "/truncate?size_mb=1&count=5", "", 200, "application/json", false},
+		{"GET", "/file-lock?workers=2&count=10", "", 200, "application/json", false},
+		{"GET", "/allocator?count=1000", "", 200, "application/json", false},
+		{"GET", "/sync-pool?goroutines=2&iterations=1000", "", 200, "application/json", false},
+		{"GET", "/crand-speed?size_gb=0.001", "", 200, "application/json", false},
+		{"GET", "/mkdir?depth=2&count=10", "", 200, "application/json", false},
+		{"GET", "/page-cache?size_mb=1", "", 200, "application/json", false},
+		{"GET", "/http-client-pool?concurrency=2&requests=20", "", 200, "application/json", false},
+		{"GET", "/websocket?count=10", "", 200, "application/json", false},
+		{"GET", "/context-overhead?count=1000", "", 200, "application/json", false},
+		{"GET", "/temp-dir-compare", "", 200, "application/json", false},
+		{"GET", "/fd-limit", "", 200, "application/json", false},
+		{"GET", "/random-read?file_mb=1&count=10", "", 200, "application/json", false},
+		{"GET", "/serialization?formats=json&size=small", "", 200, "application/json", false},
+		{"GET", "/net-pipe?count=10", "", 200, "application/json", false},
+		{"GET", "/network?count=1", "", 200, "application/json", false},
+		{"GET", "/concurrent-stat-read?files=10&workers=2", "", 200, "application/json", false},
+		{"GET", "/prealloc-write?size_mb=1", "", 200, "application/json", false},
+		{"GET", "/page-faults?size_mb=1", "", 200, "application/json", false},
+		{"GET", "/line-read?lines=5000&line_len=10", "", 200, "application/json", false},
+		{"GET", "/binary-encoding?count=1000", "", 200, "application/json", false},
+		{"GET", "/strconv?count=1000", "", 200, "application/json", false},
+		{"GET", "/time-now?count=1000", "", 200, "application/json", false},
+		{"GET", "/rand-throughput?size_gb=0.001", "", 200, "application/json", false},
+		{"GET", "/rwmutex?readers=2&writers=1&iterations=100", "", 200, "application/json", false},
+		{"GET", "/flock-vs-mutex?count=100", "", 200, "application/json", false},
+		{"GET", "/url-parse?count=100", "", 200, "application/json", false},
+		{"GET", "/http-dispatch?routes=20&requests=100", "", 200, "application/json", false},
+		{"GET", "/context-deadline?count=100", "", 200, "application/json", false},
+		{"GET", "/http-parsing?headers=5&body_kb=1&count=100", "", 200, "application/json", false},
+		{"GET", "/sqlite-conn?count=10", "", 200, "application/json", false},
+		{"GET", "/buffered-read?file_mb=1&read_kb=4", "", 200, "application/json", false},
+		{"GET", "/stat-variants?count=100", "", 200, "application/json", false},
+		{"GET", "/string-build?n=10&len=4", "", 200, "application/json", false},
+		{"GET", "/response-write?body_kb=1&count=10", "", 200, "application/json", false},
+		{"GET", "/csv?rows=100&cols=5", "", 200, "application/json", false},
+		{"GET", "/glob?files=100&pattern=*.go", "", 200, "application/json", false},
+		{"GET", "/glob?pattern=[", "", 400, "text/plain; charset=utf-8", false},
+		{"GET", "/json-stream?size=small&count=10", "", 200, "application/json", false},
+		{"GET", "/timer-accuracy?target_ms=1&count=10", "", 200, "application/json", false},
+		{"GET", "/map-iteration?size=1000&count=10", "", 200, "application/json", false},
+		{"GET", "/sync-once?goroutines=2&count=100", "", 200, "application/json", false},
+		{"GET", "/fmt-fprintf?count=100", "", 200, "application/json", false},
+		{"GET", "/big-int?bits=256&count=10", "", 200, "application/json", false},
+		{"GET", "/dial-latency?target=example.com:80", "", 403, "text/plain; charset=utf-8", false},
+		{"GET", "/multi-writer?sinks=2&size_kb=1&count=100", "", 200, "application/json", false},
+		{"GET", "/deflate?level=1&size_mb=1", "", 200, "application/json", false},
+		{"GET", "/concurrent-rw?readers=1&writers=1&duration_s=1", "", 200, "application/json", false},
+		{"GET", "/file-server?files=5&size_kb=1&requests=20", "", 200, "application/json", false},
+		{"GET", "/cross-rename?size_kb=1&count=10", "", 200, "application/json", false},
+		{"GET", "/exec-spawn?count=5", "", 200, "application/json", false},
+		{"GET", "/exec-spawn?command=/bin/sh", "", 403, "text/plain; charset=utf-8", false},
+		{"GET", "/reverse-proxy?body_kb=1&requests=10", "", 200, "application/json", false},
+		{"GET", "/readonly-fs?files=5", "", 200, "application/json", false},
+		{"GET", "/serialize-disk?count=10", "", 200, "application/json", false},
+		{"GET", "/logging?count=10", "", 200, "application/json", false},
+		{"GET", "/middleware-chain?depth=2&requests=100", "", 200, "application/json", false},
+		{"GET", "/full-system", "", 200, "application/json", false},
+		{"GET", "/meta", "", 200, "application/json", false},
+		{"GET", "/disk-info", "", 200, "application/json", false},
+		{"GET", "/benchmark-history?metric=TinyRW.Seconds", "", 200, "application/json", false},
+		{"GET", "/badge/ephemeral-disk/iops", "", 200, "image/svg+xml", false},
+		{"GET", "/badge/ephemeral-disk/iops?format=json", "", 200, "application/json", false},
+		{"GET", "/badge/other-disk/iops", "", 404, "text/plain; charset=utf-8", false},
+		{"GET", "/metrics/summary", "", 200, "application/json", false},
+		{"GET", "/results/diff", "", 400, "text/plain; charset=utf-8", false},
+		{"GET", "/report.pdf", "", 200, "application/pdf", false},
+		{"GET", "/report.pdf?from=yesterday", "", 400, "text/plain; charset=utf-8", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if tt.huge && testing.Short() {
+				t.Skip("huge size class is skipped in short mode")
+			}
+
+			req, err := http.NewRequest(tt.method, testServerURL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.status, body)
+			}
+			if ct := resp.Header.Get("content-type"); ct != tt.contentType {
+				t.Errorf("content-type = %q, want %q", ct, tt.contentType)
+			}
+			if tt.contentType == "application/json" && !json.Valid(body) {
+				t.Errorf("body is not valid JSON: %s", body)
+			}
+		})
+	}
+}
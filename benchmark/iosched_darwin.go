@@ -0,0 +1,7 @@
+package main
+
+// detectIOScheduler always returns "unknown", since only Linux exposes the
+// I/O scheduler through sysfs.
+func detectIOScheduler(device string) string {
+	return "unknown"
+}
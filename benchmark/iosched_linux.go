@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// detectIOScheduler returns the active I/O scheduler of the block device
+// behind device, such as /dev/nvme0n1p1, as listed in brackets in
+// /sys/block/<dev>/queue/scheduler. Partitions are resolved to their parent
+// disk. It returns "unknown" when there is no such file, for example for
+// network filesystems.
+func detectIOScheduler(device string) string {
+	name := filepath.Base(device)
+	if name == "" || name == "." || name == "/" {
+		return "unknown"
+	}
+
+	paths := []string{filepath.Join("/sys/block", name, "queue/scheduler")}
+	if dir, err := filepath.EvalSymlinks(filepath.Join("/sys/class/block", name)); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(dir), "queue/scheduler"))
+	}
+
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, field := range strings.Fields(string(b)) {
+			if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+				return strings.Trim(field, "[]")
+			}
+		}
+		// Devices with a single scheduler list it without brackets.
+		if fields := strings.Fields(string(b)); len(fields) == 1 {
+			return fields[0]
+		}
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func benchJSONStream(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		JSONStreamResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	size := r.URL.Query().Get("size")
+	if size == "" {
+		size = "medium"
+	}
+	if _, ok := serialSampleSizes[size]; !ok {
+		http.Error(w, fmt.Sprintf("query parameter size: unknown size %q", size), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkJSONStream(dir, size, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.JSONStreamResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type JSONStreamResult struct {
+	Size          string
+	FileBytes     int
+	Count         int
+	UnmarshalMBps float64
+	DecoderMBps   float64
+}
+
+// benchmarkJSONStream writes the serialization benchmark's sample of the
+// given size as a JSON file in dir and parses it count times, once by reading
+// the whole file and calling json.Unmarshal and once with a json.Decoder on
+// the open file. The file is opened for every parse in both cases, and is
+// read from the page cache after the first time.
+func benchmarkJSONStream(dir string, size string, count int) (*JSONStreamResult, error) {
+	b, err := json.Marshal(serialSampleSizes[size]())
+	if err != nil {
+		return nil, fmt.Errorf("encode sample: %w", err)
+	}
+
+	tmp, err := mkdirTemp(dir, "json_stream_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(tmp)
+
+	path := filepath.Join(tmp, "sample.json")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return nil, fmt.Errorf("write sample: %w", err)
+	}
+
+	res := &JSONStreamResult{Size: size, FileBytes: len(b), Count: count}
+	mb := float64(len(b)) * float64(count) / (1 << 20)
+
+	start := time.Now()
+	for range count {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, &StepError{"unmarshal", fmt.Errorf("read sample: %w", err)}
+		}
+		var out serialSample
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, &StepError{"unmarshal", fmt.Errorf("decode sample: %w", err)}
+		}
+	}
+	res.UnmarshalMBps = mb / time.Since(start).Seconds()
+
+	start = time.Now()
+	for range count {
+		if err := decodeJSONFile(path); err != nil {
+			return nil, &StepError{"decoder", err}
+		}
+	}
+	res.DecoderMBps = mb / time.Since(start).Seconds()
+
+	return res, nil
+}
+
+func decodeJSONFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open sample: %w", err)
+	}
+	defer f.Close()
+
+	var out serialSample
+	if err := json.NewDecoder(f).Decode(&out); err != nil {
+		return fmt.Errorf("decode sample: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// LatencyStats summarises a set of latency samples in milliseconds.
+type LatencyStats struct {
+	MinMs float64
+	P50Ms float64
+	P95Ms float64
+	P99Ms float64
+}
+
+func latencyStats(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	return LatencyStats{
+		MinMs: durationMs(sorted[0]),
+		P50Ms: durationMs(percentile(sorted, 50)),
+		P95Ms: durationMs(percentile(sorted, 95)),
+		P99Ms: durationMs(percentile(sorted, 99)),
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted, which must
+// not be empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted))*p/100+0.5) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func durationUs(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// runningCV accumulates samples with Welford's algorithm, so their
+// coefficient of variation can be taken without keeping them.
+type runningCV struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (c *runningCV) add(d time.Duration) {
+	c.n++
+	delta := float64(d) - c.mean
+	c.mean += delta / float64(c.n)
+	c.m2 += delta * (float64(d) - c.mean)
+}
+
+// cv returns the standard deviation of the samples divided by their mean, or
+// zero when there are fewer than two samples.
+func (c *runningCV) cv() float64 {
+	if c.n < 2 || c.mean == 0 {
+		return 0
+	}
+	return math.Sqrt(c.m2/float64(c.n)) / c.mean
+}
+
+// meanCV returns the mean coefficient of variation of the copy times in
+// perSrc, one entry per source file. Source files in a size class differ in
+// size, so a copy is only compared with other copies of the same source.
+// Sources copied fewer than twice are left out.
+func meanCV(perSrc []runningCV) float64 {
+	var sum float64
+	n := 0
+	for i := range perSrc {
+		if perSrc[i].n < 2 {
+			continue
+		}
+		sum += perSrc[i].cv()
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
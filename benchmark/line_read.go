@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func benchLineRead(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		LineReadResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	lines, err := queryInt(r, "lines", 1000000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	lineLen, err := queryInt(r, "line_len", 100, 1, 1024*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if int64(lines)*int64(lineLen+1) > maxSizeRangeBytes {
+		http.Error(w, fmt.Sprintf("query parameters lines and line_len: file must be at most %d bytes", maxSizeRangeBytes), 400)
+		return
+	}
+
+	res, err := benchmarkLineRead(dir, lines, lineLen)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.LineReadResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// LineReadResult compares reading a file line by line with bufio.Scanner
+// against loading it whole and splitting it. BreakevenBytes is the smallest
+// file size tried at which the scanner was faster, or zero if it never was.
+type LineReadResult struct {
+	Lines          int
+	LineLen        int
+	FileSize       int64
+	Scanner        LineReadRun
+	FullLoad       LineReadRun
+	BreakevenBytes int64
+}
+
+type LineReadRun struct {
+	LinesPerSec float64
+	MBps        float64
+}
+
+// lineReadMinLines is the smallest file of the breakeven sweep.
+const lineReadMinLines = 1024
+
+// benchmarkLineRead writes a file of lines lines of lineLen bytes and reads
+// it with both approaches. To find the breakeven, the same is done for files
+// of lineReadMinLines lines, doubling up to lines. The files are read right
+// after being written, so this measures the parsing rather than the disk.
+func benchmarkLineRead(dir string, lines int, lineLen int) (*LineReadResult, error) {
+	res := &LineReadResult{Lines: lines, LineLen: lineLen}
+
+	sizes := []int{}
+	for n := lineReadMinLines; n < lines; n *= 2 {
+		sizes = append(sizes, n)
+	}
+	sizes = append(sizes, lines)
+
+	for _, n := range sizes {
+		size, scanner, fullLoad, err := timeLineRead(dir, n, lineLen)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.BreakevenBytes == 0 && scanner < fullLoad {
+			res.BreakevenBytes = size
+		}
+
+		if n == lines {
+			res.FileSize = size
+			res.Scanner = lineReadRun(n, size, scanner)
+			res.FullLoad = lineReadRun(n, size, fullLoad)
+		}
+	}
+
+	return res, nil
+}
+
+func lineReadRun(lines int, size int64, d time.Duration) LineReadRun {
+	return LineReadRun{
+		LinesPerSec: float64(lines) / d.Seconds(),
+		MBps:        float64(size) / (1 << 20) / d.Seconds(),
+	}
+}
+
+// timeLineRead writes a file of lines lines and returns its size and how long
+// each approach took to count its lines.
+func timeLineRead(dir string, lines int, lineLen int) (size int64, scanner, fullLoad time.Duration, err error) {
+	f, err := createTemp(dir, "line_read_*")
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	// fillContent may produce newlines, so lines are made of a fixed
+	// character instead.
+	line := append(bytes.Repeat([]byte{'x'}, lineLen), '\n')
+
+	bw := bufio.NewWriter(f)
+	for range lines {
+		if _, err := bw.Write(line); err != nil {
+			return 0, 0, 0, fmt.Errorf("write to temp file: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, 0, 0, fmt.Errorf("write to temp file: %w", err)
+	}
+	size = int64(lines) * int64(len(line))
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, 0, fmt.Errorf("seek temp file: %w", err)
+	}
+
+	start := time.Now()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), len(line))
+	n := 0
+	for sc.Scan() {
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return 0, 0, 0, fmt.Errorf("scan temp file: %w", err)
+	}
+
+	scanner = time.Since(start)
+
+	if n != lines {
+		return 0, 0, 0, fmt.Errorf("scanner counted %d lines, want %d", n, lines)
+	}
+
+	start = time.Now()
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("read temp file: %w", err)
+	}
+	// The file ends in a newline, which leaves an empty last element.
+	n = len(strings.Split(string(b), "\n")) - 1
+
+	fullLoad = time.Since(start)
+
+	if n != lines {
+		return 0, 0, 0, fmt.Errorf("split counted %d lines, want %d", n, lines)
+	}
+
+	return size, scanner, fullLoad, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// benchmarkMu keeps benchmarks on this instance from running concurrently.
+var benchmarkMu sync.Mutex
+
+const (
+	redisLockKey = "wheretodeploy:benchmark-lock"
+
+	// redisLockTTL bounds how long a lock outlives a pod that died while
+	// holding it.
+	redisLockTTL = time.Hour
+)
+
+// redisReleaseScript deletes the lock only if this host still holds it.
+var redisReleaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// BenchmarkLock serialises benchmark runs across every pod sharing a Redis
+// instance.
+type BenchmarkLock struct {
+	client   *redis.Client
+	hostname string
+}
+
+// newBenchmarkLock returns nil unless url is set.
+func newBenchmarkLock(url string) (*BenchmarkLock, error) {
+	if url == "" {
+		return nil, nil
+	}
+
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("config: redis_url (BM_REDIS_URL): %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	return &BenchmarkLock{redis.NewClient(opts), hostname}, nil
+}
+
+var errLockHeld = errors.New("benchmark lock held by another pod")
+
+// Acquire takes the lock. If another pod holds it, errLockHeld is returned
+// together with that pod's hostname.
+func (l *BenchmarkLock) Acquire(ctx context.Context) (holder string, err error) {
+	ok, err := l.client.SetNX(ctx, redisLockKey, l.hostname, redisLockTTL).Result()
+	if err != nil {
+		return "", fmt.Errorf("acquire redis lock: %w", err)
+	}
+	if ok {
+		return "", nil
+	}
+
+	holder, err = l.client.Get(ctx, redisLockKey).Result()
+	if errors.Is(err, redis.Nil) {
+		// Released between SETNX and GET; the caller may simply retry.
+		return "", errLockHeld
+	} else if err != nil {
+		return "", fmt.Errorf("get redis lock holder: %w", err)
+	}
+
+	return holder, errLockHeld
+}
+
+func (l *BenchmarkLock) Release(ctx context.Context) error {
+	if err := redisReleaseScript.Run(ctx, l.client, []string{redisLockKey}, l.hostname).Err(); err != nil {
+		return fmt.Errorf("release redis lock: %w", err)
+	}
+	return nil
+}
+
+// exclusive wraps a benchmark handler so that only one benchmark runs at a
+// time, on this instance and, when BM_REDIS_URL is set, across all pods.
+// Requests that arrive while a benchmark is running get HTTP 409. It wraps
+// the disk benchmarks, those that open sockets, and those that change
+// process-wide state or can use up memory or processes when run in
+// parallel. The other CPU benchmarks and the read-only routes are not
+// wrapped.
+func exclusive(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		release, ok := lockBenchmark(w, r)
+		if !ok {
+			return
+		}
+		defer release()
+
+		h(w, r)
+	}
+}
+
+// lockBenchmark takes the lock exclusive takes, for handlers that only need
+// it for part of their work. If the lock is held it writes the 409 response,
+// and on other errors a 500, and returns false. Otherwise the caller must
+// call release when done.
+func lockBenchmark(w http.ResponseWriter, r *http.Request) (release func(), ok bool) {
+	type Response struct {
+		Error  string `json:"error"`
+		Holder string `json:"holder,omitempty"`
+	}
+
+	conflict := func(holder string) {
+		writePrettyJSON(w, 409, Response{"benchmark already running", holder}, queryPretty(r))
+	}
+
+	if !benchmarkMu.TryLock() {
+		hostname, _ := os.Hostname()
+		conflict(hostname)
+		return nil, false
+	}
+
+	if benchmarkLock != nil {
+		holder, err := benchmarkLock.Acquire(r.Context())
+		if errors.Is(err, errLockHeld) {
+			benchmarkMu.Unlock()
+			conflict(holder)
+			return nil, false
+		} else if err != nil {
+			benchmarkMu.Unlock()
+			fmt.Println(requestIDFromContext(r.Context()), err)
+			writeJSONError(w, 500, err, "")
+			return nil, false
+		}
+	}
+
+	return func() {
+		if benchmarkLock != nil {
+			if err := benchmarkLock.Release(context.Background()); err != nil {
+				fmt.Println(requestIDFromContext(r.Context()), err)
+			}
+		}
+		benchmarkMu.Unlock()
+	}, true
+}
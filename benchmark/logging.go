@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+func benchLogging(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		LoggingResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 10000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkLogging(dir, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.LoggingResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type LoggingResult struct {
+	Count                    int
+	FileLoggingLinesPerSec   float64
+	FileLoggingMBps          float64
+	StderrLoggingLinesPerSec float64
+	StderrLoggingMBps        float64
+	SlogJSONLinesPerSec      float64
+	SlogJSONMBps             float64
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// benchmarkLogging writes count lines with a log.Logger to a temp file in
+// dir, the same with a log.Logger to stderr, which ends up in the server's
+// own log, and count records with a slog JSON handler to the temp file. Each
+// line carries a string, an int and a duration, like a request log.
+func benchmarkLogging(dir string, count int) (*LoggingResult, error) {
+	f, err := createTemp(dir, "logging_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	res := &LoggingResult{Count: count}
+
+	file := &countingWriter{w: f}
+	fileLogger := log.New(file, "", log.LstdFlags)
+	lines, mb := timeLogging(file, count, func(i int) {
+		fileLogger.Printf("GET /path status=200 bytes=%d took=%s", i, time.Duration(i))
+	})
+	res.FileLoggingLinesPerSec, res.FileLoggingMBps = lines, mb
+
+	stderr := &countingWriter{w: os.Stderr}
+	stderrLogger := log.New(stderr, "", log.LstdFlags)
+	lines, mb = timeLogging(stderr, count, func(i int) {
+		stderrLogger.Printf("GET /path status=200 bytes=%d took=%s", i, time.Duration(i))
+	})
+	res.StderrLoggingLinesPerSec, res.StderrLoggingMBps = lines, mb
+
+	slogFile := &countingWriter{w: f}
+	slogger := slog.New(slog.NewJSONHandler(slogFile, nil))
+	lines, mb = timeLogging(slogFile, count, func(i int) {
+		slogger.Info("GET /path", "status", 200, "bytes", i, "took", time.Duration(i))
+	})
+	res.SlogJSONLinesPerSec, res.SlogJSONMBps = lines, mb
+
+	return res, nil
+}
+
+// timeLogging calls logLine count times and returns the lines and the MB
+// written through w per second.
+func timeLogging(w *countingWriter, count int, logLine func(i int)) (float64, float64) {
+	start := time.Now()
+	for i := range count {
+		logLine(i)
+	}
+	since := time.Since(start).Seconds()
+
+	return float64(count) / since, float64(w.n) / (1 << 20) / since
+}
@@ -1,164 +1,663 @@
+// Command benchmark serves disk, CPU, memory and network benchmarks over
+// HTTP. It builds on Linux and macOS only. The Linux-only parts are bind
+// mounts, fadvise, fallocate, per-thread rusage and the sysfs I/O scheduler.
+// They return errors.ErrUnsupported on macOS, or "unknown" for the
+// scheduler, from the _darwin.go files.
 package main
 
 import (
-	crand "crypto/rand"
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
-func mustGetEnv(name string) string {
-	val := os.Getenv(name)
-	if val == "" {
-		panic("envvar " + name + " must be set")
-	}
-	return val
-}
-
 var (
-	ephemeralDir  string = mustGetEnv("BM_EPHEMERAL_DIR")
-	persistentDir string = mustGetEnv("BM_PERSISTENT_DIR")
+	ephemeralDir  string
+	persistentDir string
+
+	resultStore         *ResultStore
+	influxDBExporter    *InfluxDBExporter
+	pushgatewayExporter *PushgatewayExporter
+	benchmarkLock       *BenchmarkLock
+
+	srcFileCount   int
+	copyBufferSize int
+	warmupRuns     int
+	classTimeouts  ClassTimeouts
+	maxCV          float64
+	maxRetries     int
+	verifyWrites   bool
+	noCleanup      bool
 )
 
 func main() {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/persistent-disk", benchPersistentDisk)
-	mux.HandleFunc("/ephemeral-disk", benchEphemeralDisk)
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := applyConfig(cfg); err != nil {
+		log.Fatalln(err)
+	}
+
+	srv, err := newServer(cfg)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	srv.Addr = ":5555"
 
-	if err := http.ListenAndServe(":5555", mux); err != nil {
+	cleanupOnSignal()
+
+	certFile, keyFile := cfg.TLS.CertFile, cfg.TLS.KeyFile
+	if certFile == "" && cfg.TLS.SelfSigned {
+		if certFile, keyFile, err = selfSignedCert(persistentDir); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if certFile != "" {
+		err = srv.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
+// newServer registers every endpoint and returns the server with the timeouts
+// from cfg. applyConfig must have been called with cfg. The caller sets Addr
+// or passes its own listener to Serve.
+func newServer(cfg *Config) (*http.Server, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/persistent-disk", exclusive(benchPersistentDisk))
+	mux.HandleFunc("/ephemeral-disk", exclusive(benchEphemeralDisk))
+	mux.HandleFunc("/incremental-write", exclusive(benchIncrementalWrite))
+	mux.HandleFunc("/read-after-write", exclusive(benchReadAfterWrite))
+	mux.HandleFunc("/truncate", exclusive(benchTruncate))
+	mux.HandleFunc("/file-lock", exclusive(benchFileLock))
+	mux.HandleFunc("/allocator", exclusive(benchAllocator))
+	mux.HandleFunc("/sync-pool", exclusive(benchSyncPool))
+	mux.HandleFunc("/crand-speed", benchCRandSpeed)
+	mux.HandleFunc("/mkdir", exclusive(benchMkdirAll))
+	mux.HandleFunc("/page-cache", exclusive(benchPageCache))
+	mux.HandleFunc("/http-client-pool", exclusive(benchHTTPClientPool))
+	mux.HandleFunc("/websocket", exclusive(benchWebSocket))
+	mux.HandleFunc("/context-overhead", benchContextOverhead)
+	mux.HandleFunc("/temp-dir-compare", exclusive(benchTempDirCompare))
+	mux.HandleFunc("/fd-limit", exclusive(benchFDLimit))
+	mux.HandleFunc("/random-read", exclusive(benchRandomRead))
+	mux.HandleFunc("/serialization", benchSerialization)
+	mux.HandleFunc("/net-pipe", exclusive(benchNetPipe))
+	mux.HandleFunc("/network", exclusive(benchNetwork))
+	mux.HandleFunc("/concurrent-stat-read", exclusive(benchConcurrentStatRead))
+	mux.HandleFunc("/prealloc-write", exclusive(benchPreAllocWrite))
+	mux.HandleFunc("/page-faults", exclusive(benchPageFaults))
+	mux.HandleFunc("/line-read", exclusive(benchLineRead))
+	mux.HandleFunc("/binary-encoding", benchBinaryEncoding)
+	mux.HandleFunc("/strconv", benchStrconv)
+	mux.HandleFunc("/time-now", benchTimeNow)
+	mux.HandleFunc("/rand-throughput", benchRandThroughput)
+	mux.HandleFunc("/rwmutex", benchRWMutex)
+	mux.HandleFunc("/flock-vs-mutex", exclusive(benchFlockVsMutex))
+	mux.HandleFunc("/url-parse", benchURLParse)
+	mux.HandleFunc("/http-dispatch", benchHTTPDispatch)
+	mux.HandleFunc("/context-deadline", benchContextDeadline)
+	mux.HandleFunc("/http-parsing", benchHTTPParsing)
+	mux.HandleFunc("/sqlite-conn", exclusive(benchSQLiteConn))
+	mux.HandleFunc("/buffered-read", exclusive(benchBufferedRead))
+	mux.HandleFunc("/stat-variants", exclusive(benchStatVariants))
+	mux.HandleFunc("/string-build", benchStringBuild)
+	mux.HandleFunc("/response-write", benchResponseWrite)
+	mux.HandleFunc("/csv", benchCSV)
+	mux.HandleFunc("/glob", exclusive(benchGlob))
+	mux.HandleFunc("/json-stream", exclusive(benchJSONStream))
+	mux.HandleFunc("/timer-accuracy", benchTimerAccuracy)
+	mux.HandleFunc("/map-iteration", benchMapIteration)
+	mux.HandleFunc("/sync-once", benchSyncOnce)
+	mux.HandleFunc("/fmt-fprintf", benchFmtFprintf)
+	mux.HandleFunc("/big-int", benchBigInt)
+	mux.HandleFunc("/dial-latency", exclusive(benchDialLatency))
+	mux.HandleFunc("/multi-writer", benchMultiWriter)
+	mux.HandleFunc("/deflate", exclusive(benchDeflate))
+	mux.HandleFunc("/concurrent-rw", exclusive(benchConcurrentRW))
+	mux.HandleFunc("/file-server", exclusive(benchFileServer))
+	mux.HandleFunc("/cross-rename", exclusive(benchCrossRename))
+	mux.HandleFunc("/exec-spawn", exclusive(benchExecSpawn))
+	mux.HandleFunc("/reverse-proxy", exclusive(benchReverseProxy))
+	mux.HandleFunc("/readonly-fs", exclusive(benchReadOnlyFS))
+	mux.HandleFunc("/serialize-disk", exclusive(benchSerializeDisk))
+	mux.HandleFunc("/logging", exclusive(benchLogging))
+	mux.HandleFunc("/middleware-chain", benchMiddlewareChain)
+	mux.HandleFunc("/full-system", exclusive(benchFullSystem))
+	mux.HandleFunc("GET /meta", metaHandler)
+	mux.HandleFunc("GET /disk-info", diskInfoHandler)
+	mux.HandleFunc("GET /benchmark-history", benchmarkHistory)
+	mux.HandleFunc("GET /badge/{disk}/{metric}", benchBadge)
+	mux.HandleFunc("GET /metrics/summary", metricsSummary)
+	mux.HandleFunc("GET /results/diff", benchResultsDiff)
+	mux.HandleFunc("GET /report.pdf", benchReportPDF)
+
+	if cfg.Debug {
+		mux.HandleFunc("POST /debug/verify-signature", debugVerifySignature)
+	}
+
+	if apiKey != "" {
+		mux.HandleFunc("GET /cleanup", requireAPIKey(exclusive(cleanupHandler)))
+	}
+
+	if err := loadPlugins(mux, cfg.PluginDir); err != nil {
+		return nil, err
+	}
+
+	return &http.Server{
+		Handler:           RequestIDMiddleware(mux),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}, nil
+}
+
+// benchEphemeralDisk runs the disk benchmark against ephemeralDir. GET runs
+// the default size classes and is deprecated in favour of POST, which accepts
+// a BenchmarkRequest body.
 func benchEphemeralDisk(w http.ResponseWriter, r *http.Request) {
 	type Response struct {
-		DiskBenchmarkResult
+		RequestID string
+		DiskMeta
+		diskBenchmarkResultJSON
 	}
 
 	var response Response
 
-	w.Header().Add("content-type", "application/json")
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
 
-	diskRes, err := benchmarkRWDisk(ephemeralDir)
+	opts, ok := diskOptionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	diskRes, err := benchmarkRWDisk(r.Context(), ephemeralDir, opts)
 	if err != nil {
-		fmt.Println(err)
-		w.WriteHeader(500)
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
 		return
 	}
 
-	response.DiskBenchmarkResult = *diskRes
+	response.diskBenchmarkResultJSON = diskRes.toJSON()
 
-	if b, err := json.Marshal(response); err != nil {
-		w.WriteHeader(500)
-		return
-	} else {
-		w.Write(b)
-		return
+	if err := resultStore.Append(StoredResult{ID: response.RequestID, Timestamp: time.Now(), Disk: "ephemeral", Result: *diskRes}); err != nil {
+		fmt.Println(response.RequestID, err)
 	}
+
+	influxDBExporter.Export("ephemeral", *diskRes)
+	pushgatewayExporter.Export("ephemeral", *diskRes)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
 }
 
+// benchPersistentDisk runs the disk benchmark against persistentDir. GET runs
+// the default size classes and is deprecated in favour of POST, which accepts
+// a BenchmarkRequest body.
 func benchPersistentDisk(w http.ResponseWriter, r *http.Request) {
 	type Response struct {
-		DiskBenchmarkResult
+		RequestID string
+		DiskMeta
+		diskBenchmarkResultJSON
 	}
 
 	var response Response
 
-	w.Header().Add("content-type", "application/json")
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
 
-	diskRes, err := benchmarkRWDisk(persistentDir)
+	opts, ok := diskOptionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	diskRes, err := benchmarkRWDisk(r.Context(), persistentDir, opts)
 	if err != nil {
-		fmt.Println(err)
-		w.WriteHeader(500)
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
 		return
 	}
 
-	response.DiskBenchmarkResult = *diskRes
+	response.diskBenchmarkResultJSON = diskRes.toJSON()
 
-	if b, err := json.Marshal(response); err != nil {
-		w.WriteHeader(500)
-		return
-	} else {
-		w.Write(b)
-		return
+	if err := resultStore.Append(StoredResult{ID: response.RequestID, Timestamp: time.Now(), Disk: "persistent", Result: *diskRes}); err != nil {
+		fmt.Println(response.RequestID, err)
 	}
+
+	influxDBExporter.Export("persistent", *diskRes)
+	pushgatewayExporter.Export("persistent", *diskRes)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
 }
 
 type DiskBenchmarkResult struct {
-	TinyRW   *DiskResult
-	SmallRW  *DiskResult
-	MediumRW *DiskResult
-	LargeRW  *DiskResult
-	HugeRW   *DiskResult
+	TinyRW   *DiskResultWithMeta
+	SmallRW  *DiskResultWithMeta
+	MediumRW *DiskResultWithMeta
+	LargeRW  *DiskResultWithMeta
+	HugeRW   *DiskResultWithMeta
+
+	// CopyBufferSize is the io.CopyBuffer buffer the files were copied with.
+	CopyBufferSize int
 }
 
-func benchmarkRWDisk(dir string) (*DiskBenchmarkResult, error) {
-	res := &DiskBenchmarkResult{}
+// DiskResultWithMeta is the outcome of a size class that was run. DiskResult
+// is nil when the class hit its timeout.
+type DiskResultWithMeta struct {
+	*DiskResult
+	TimedOut bool
+}
 
-	if rw, err := writeFilesInSizeRangeToDir(dir, 100000, SizeRange{128, 1024}); err != nil {
-		return nil, err
-	} else {
-		res.TinyRW = rw
+// class returns the field holding the size class with the given name.
+func (r *DiskBenchmarkResult) class(name string) **DiskResultWithMeta {
+	switch name {
+	case "tiny":
+		return &r.TinyRW
+	case "small":
+		return &r.SmallRW
+	case "medium":
+		return &r.MediumRW
+	case "large":
+		return &r.LargeRW
+	case "huge":
+		return &r.HugeRW
 	}
+	panic("unknown size class " + name)
+}
 
-	if rw, err := writeFilesInSizeRangeToDir(dir, 10000, SizeRange{1024, 1024 * 1024}); err != nil {
-		return nil, err
-	} else {
-		res.SmallRW = rw
+// set stores the outcome of the size class with the given name.
+func (r *DiskBenchmarkResult) set(name string, rw *DiskResultWithMeta) {
+	*r.class(name) = rw
+}
+
+// get returns the result of the size class with the given name, or nil if it
+// did not run or timed out.
+func (r *DiskBenchmarkResult) get(name string) *DiskResult {
+	if rw := *r.class(name); rw != nil {
+		return rw.DiskResult
 	}
+	return nil
+}
 
-	if rw, err := writeFilesInSizeRangeToDir(dir, 1000, SizeRange{1024 * 1024, 16 * 1024 * 1024}); err != nil {
-		return nil, err
-	} else {
-		res.MediumRW = rw
+// IsPartial reports whether any size class did not complete, because it was
+// not selected or timed out.
+func (r *DiskBenchmarkResult) IsPartial() bool {
+	return len(r.CompletedClasses()) < len(defaultSizeClasses())
+}
+
+// CompletedClasses returns the names of the size classes that have a result,
+// smallest first.
+func (r *DiskBenchmarkResult) CompletedClasses() []string {
+	var names []string
+	for _, class := range defaultSizeClasses() {
+		if r.get(class.Name) != nil {
+			names = append(names, class.Name)
+		}
 	}
+	return names
+}
 
-	if rw, err := writeFilesInSizeRangeToDir(dir, 100, SizeRange{16 * 1024 * 1024, 128 * 1024 * 1024}); err != nil {
-		return nil, err
-	} else {
-		res.LargeRW = rw
+// diskBenchmarkFields has the fields of DiskBenchmarkResult but not its
+// methods, so it can be encoded without recursing into MarshalJSON.
+type diskBenchmarkFields DiskBenchmarkResult
+
+// diskBenchmarkResultJSON is the encoded form of a DiskBenchmarkResult.
+// Handlers embed it rather than the result itself, since an embedded
+// MarshalJSON would take over the whole response.
+type diskBenchmarkResultJSON struct {
+	diskBenchmarkFields
+	IsPartial bool
+}
+
+func (r *DiskBenchmarkResult) toJSON() diskBenchmarkResultJSON {
+	return diskBenchmarkResultJSON{diskBenchmarkFields(*r), r.IsPartial()}
+}
+
+func (r DiskBenchmarkResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.toJSON())
+}
+
+// SizeClass describes one step of the disk benchmark: Count files copied with
+// sizes spread over SizeRange.
+type SizeClass struct {
+	Name      string
+	Count     int
+	SizeRange SizeRange
+
+	// Timeout bounds how long the class may run; zero means no limit.
+	Timeout time.Duration
+}
+
+func defaultSizeClasses() []SizeClass {
+	return []SizeClass{
+		{"tiny", 100000, SizeRange{128, 1024}, classTimeouts.Tiny},
+		{"small", 10000, SizeRange{1024, 1024 * 1024}, classTimeouts.Small},
+		{"medium", 1000, SizeRange{1024 * 1024, 16 * 1024 * 1024}, classTimeouts.Medium},
+		{"large", 100, SizeRange{16 * 1024 * 1024, 128 * 1024 * 1024}, classTimeouts.Large},
+		{"huge", 10, SizeRange{128 * 1024 * 1024, 512 * 1024 * 1024}, classTimeouts.Huge},
 	}
+}
 
-	if rw, err := writeFilesInSizeRangeToDir(dir, 10, SizeRange{128 * 1024 * 1024, 512 * 1024 * 1024}); err != nil {
-		return nil, err
-	} else {
-		res.HugeRW = rw
+// DiskOptions tunes a disk benchmark run.
+type DiskOptions struct {
+	Classes []SizeClass
+
+	// SrcFileCount is how many source files each size class copies from.
+	SrcFileCount int
+}
+
+// benchmarkRWDisk runs every size class in opts, after warmupRuns untimed runs
+// whose results are discarded.
+func benchmarkRWDisk(ctx context.Context, dir string, opts DiskOptions) (*DiskBenchmarkResult, error) {
+	for i := range warmupRuns {
+		start := time.Now()
+		if _, err := runDiskClasses(ctx, dir, opts, 0); err != nil {
+			return nil, fmt.Errorf("warm-up run %d: %w", i+1, err)
+		}
+		log.Printf("warm-up run %d/%d in %s took %s", i+1, warmupRuns, dir, time.Since(start))
+	}
+
+	return runDiskClasses(ctx, dir, opts, maxRetries)
+}
+
+// runDiskClasses runs every size class in opts. A class whose copy times vary
+// by more than maxCV between copies of the same source file is run again, up
+// to retries times, and the run with the lowest variation is kept. A class
+// that exceeds its timeout is recorded as timed out and the remaining classes
+// still run; a retry that times out ends the retries for its class.
+func runDiskClasses(ctx context.Context, dir string, opts DiskOptions, retries int) (*DiskBenchmarkResult, error) {
+	res := &DiskBenchmarkResult{CopyBufferSize: copyBufferSize}
+
+	for _, class := range opts.Classes {
+		sizeRange, err := NewSizeRange(class.SizeRange.min, class.SizeRange.max)
+		if err != nil {
+			return nil, &StepError{class.Name, err}
+		}
+
+		rw, err := runDiskClass(ctx, dir, class, sizeRange, opts.SrcFileCount)
+
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			res.set(class.Name, &DiskResultWithMeta{TimedOut: true})
+			continue
+		} else if err != nil {
+			return nil, &StepError{class.Name, err}
+		}
+
+		for i := 1; i <= retries && rw.FinalCV > maxCV; i++ {
+			log.Printf("%s in %s: CV %.3f is above %.3f, retry %d/%d", class.Name, dir, rw.FinalCV, maxCV, i, retries)
+
+			retry, err := runDiskClass(ctx, dir, class, sizeRange, opts.SrcFileCount)
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				break
+			} else if err != nil {
+				return nil, &StepError{class.Name, err}
+			}
+
+			if retry.FinalCV < rw.FinalCV {
+				rw = retry
+			}
+			rw.Retries = i
+		}
+
+		res.set(class.Name, &DiskResultWithMeta{DiskResult: rw})
 	}
 
 	return res, nil
 }
 
+// runDiskClass runs writeFilesInSizeRangeToDir once for class, within the
+// class timeout.
+func runDiskClass(ctx context.Context, dir string, class SizeClass, sizeRange SizeRange, srcFileCount int) (*DiskResult, error) {
+	if class.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, class.Timeout)
+		defer cancel()
+	}
+
+	return writeFilesInSizeRangeToDir(ctx, dir, class.Count, sizeRange, srcFileCount)
+}
+
 type SizeRange struct {
 	min int
 	max int
 }
 
+// maxSizeRangeBytes caps the largest file a size class may write.
+const maxSizeRangeBytes = 10 * 1024 * 1024 * 1024
+
+// maxClassCount caps how many files a size class may copy, which bounds the
+// memory kept for their copy times.
+const maxClassCount = 10000000
+
+// NewSizeRange returns the range of file sizes from min up to but not
+// including max.
+func NewSizeRange(min, max int) (SizeRange, error) {
+	if min < 1 {
+		return SizeRange{}, fmt.Errorf("size range: min (%d) must be at least 1", min)
+	}
+	if max <= min {
+		return SizeRange{}, fmt.Errorf("size range: min (%d) must be less than max (%d)", min, max)
+	}
+	if max > maxSizeRangeBytes {
+		return SizeRange{}, fmt.Errorf("size range: max (%d) must be at most %d", max, maxSizeRangeBytes)
+	}
+	return SizeRange{min, max}, nil
+}
+
+// srcFileSize returns the size of source file i of n, spaced linearly from
+// the start of sizeRange.
+func srcFileSize(sizeRange SizeRange, i, n int) int {
+	return sizeRange.min + int(float32(sizeRange.max-sizeRange.min)*(float32(i)/float32(n)))
+}
+
+// srcFileSizes returns the sizes srcFileSize gives each of n source files.
+func srcFileSizes(sizeRange SizeRange, n int) []float64 {
+	sizes := make([]float64, n)
+	for i := range sizes {
+		sizes[i] = float64(srcFileSize(sizeRange, i, n))
+	}
+	return sizes
+}
+
+// maxSrcFileCount is the most source files every default size class can
+// spread over its range and still pass validateSizeDistribution. The huge
+// class is the tightest: with 16 files, its two largest are within 5% of
+// each other.
+const maxSrcFileCount = 15
+
+// validateSizeDistribution checks that files, the source files of a size
+// class, cover a real range of sizes, see checkSizeDistribution.
+func validateSizeDistribution(files []string) error {
+	sizes := make([]float64, len(files))
+	for i, name := range files {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return fmt.Errorf("stat src file: %w", err)
+		}
+		sizes[i] = float64(fi.Size())
+	}
+	return checkSizeDistribution(sizes)
+}
+
+// checkSizeDistribution checks that sizes cover a real range: the
+// coefficient of variation must be at least 0.1 and no two sizes may be
+// within 5% of each other. A single size is always accepted.
+func checkSizeDistribution(sizes []float64) error {
+	if len(sizes) < 2 {
+		return nil
+	}
+
+	sizes = slices.Clone(sizes)
+	slices.Sort(sizes)
+
+	var mean float64
+	for _, size := range sizes {
+		mean += size
+	}
+	mean /= float64(len(sizes))
+
+	var variance float64
+	for _, size := range sizes {
+		variance += (size - mean) * (size - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(sizes)))
+
+	if stddev/mean < 0.1 {
+		return fmt.Errorf("src file sizes too uniform: stddev/mean is %.3f, want at least 0.1", stddev/mean)
+	}
+
+	for i := 1; i < len(sizes); i++ {
+		if sizes[i]-sizes[i-1] < 0.05*sizes[i] {
+			return fmt.Errorf("src file sizes too close: %.0f and %.0f bytes are within 5%% of each other", sizes[i-1], sizes[i])
+		}
+	}
+
+	return nil
+}
+
 type DiskResult struct {
 	Seconds float32
 	Count   int
 	Bytes   int64
+
+	// FinalCV is the mean coefficient of variation of the copy times of each
+	// source file in the kept run, and Retries how many times the size class
+	// was rerun because it was above maxCV.
+	Retries int
+	FinalCV float64
+
+	// VerifiedCount is how many copies were read back and matched their
+	// source with BM_VERIFY_WRITES set. A mismatch fails the run.
+	VerifiedCount int
+}
+
+// ThroughputMBps returns the bytes written per second in MiB. It is zero for
+// a nil or empty result.
+func (r *DiskResult) ThroughputMBps() float64 {
+	if r == nil || r.Seconds == 0 {
+		return 0
+	}
+	return float64(r.Bytes) / (1 << 20) / float64(r.Seconds)
+}
+
+// IOPS returns the files written per second. It is zero for a nil or empty
+// result.
+func (r *DiskResult) IOPS() float64 {
+	if r == nil || r.Seconds == 0 {
+		return 0
+	}
+	return float64(r.Count) / float64(r.Seconds)
+}
+
+// diskResultJSON is the encoded form of a DiskResult, with the derived
+// metrics next to the raw fields.
+type diskResultJSON struct {
+	Seconds        float32
+	Count          int
+	Bytes          int64
+	ThroughputMBps float64
+	IOPS           float64
+	Retries        int
+	FinalCV        float64
+
+	VerifiedCount int
+}
+
+func (r *DiskResult) toJSON() *diskResultJSON {
+	if r == nil {
+		return nil
+	}
+	return &diskResultJSON{r.Seconds, r.Count, r.Bytes, r.ThroughputMBps(), r.IOPS(), r.Retries, r.FinalCV, r.VerifiedCount}
+}
+
+func (r DiskResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.toJSON())
+}
+
+// MarshalText renders r on one line for logs, for example
+// "count=1000 bytes=10.5 GiB iops=234.5 throughput=125.3 MB/s elapsed=4.27s".
+func (r *DiskResult) MarshalText() ([]byte, error) {
+	if r == nil {
+		return []byte("<nil>"), nil
+	}
+	return fmt.Appendf(nil, "count=%d bytes=%s iops=%.1f throughput=%.1f MB/s elapsed=%.2fs",
+		r.Count, formatBytes(r.Bytes), r.IOPS(), r.ThroughputMBps(), r.Seconds), nil
 }
 
-func writeFilesInSizeRangeToDir(dir string, count int, sizeRange SizeRange) (*DiskResult, error) {
-	var srcFiles []string
-	srcFilesCount := 10
+// formatBytes formats n with the largest binary unit that keeps it at or
+// above 1.
+func formatBytes(n int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	v, i := float64(n), 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
 
-	buf := make([]byte, 32*1024)
+// MarshalJSON is needed because the one promoted from DiskResult would drop
+// TimedOut.
+func (r DiskResultWithMeta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*diskResultJSON
+		TimedOut bool
+	}{r.DiskResult.toJSON(), r.TimedOut})
+}
+
+// writeFilesInSizeRangeToDir creates srcFilesCount source files with sizes
+// spread over sizeRange and times count copies of them. ctx is checked
+// between files, so a single large copy can run past the deadline.
+func writeFilesInSizeRangeToDir(ctx context.Context, dir string, count int, sizeRange SizeRange, srcFilesCount int) (_ *DiskResult, err error) {
+	var srcFiles, destFiles []string
+
+	defer func() {
+		if noCleanup {
+			keepFiles(dir, slices.Concat(srcFiles, destFiles))
+		} else if err != nil {
+			for _, name := range srcFiles {
+				removeTemp(name)
+			}
+		}
+	}()
+
+	buf := make([]byte, copyBufferSize)
 
 	for i := range srcFilesCount {
-		if f, err := os.CreateTemp(dir, "small_file_src_*"); err != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if f, err := createTemp(dir, "small_file_src_*"); err != nil {
 			return nil, fmt.Errorf("create temp file: %w", err)
 		} else {
 			written := 0
-			maxSize := sizeRange.min + int(float32(sizeRange.max-sizeRange.min)*(float32(i)/float32(srcFilesCount)))
+			maxSize := srcFileSize(sizeRange, i, srcFilesCount)
 			for written < maxSize {
-				if _, err := crand.Read(buf); err != nil {
+				if err := fillContent(buf); err != nil {
 					f.Close()
-					return nil, fmt.Errorf("random bytes: %w", err)
+					return nil, err
 				} else {
 					maxRead := min(1024, maxSize-written)
 					if w, err := f.Write(buf[0:maxRead]); err != nil {
@@ -175,11 +674,35 @@ func writeFilesInSizeRangeToDir(dir string, count int, sizeRange SizeRange) (*Di
 		}
 	}
 
+	if err := validateSizeDistribution(srcFiles); err != nil {
+		return nil, err
+	}
+
+	var srcHashes [][]byte
+	if verifyWrites {
+		for _, name := range srcFiles {
+			sum, err := hashFile(name)
+			if err != nil {
+				return nil, fmt.Errorf("hash src file: %w", err)
+			}
+			srcHashes = append(srcHashes, sum)
+		}
+	}
+
 	start := time.Now()
 
 	totalWritten := int64(0)
+	copyTimes := make([]runningCV, len(srcFiles))
+	verified := 0
+	var verifyTime time.Duration
 
 	for i := range count {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		fileStart := time.Now()
+
 		ii := i
 		src := srcFiles[ii%len(srcFiles)]
 		srcf, err := os.Open(src)
@@ -187,7 +710,7 @@ func writeFilesInSizeRangeToDir(dir string, count int, sizeRange SizeRange) (*Di
 			return nil, fmt.Errorf("open src file: %w", err)
 		}
 
-		destf, err := os.CreateTemp(dir, "small_file_dest_*")
+		destf, err := createTemp(dir, "small_file_dest_*")
 		if err != nil {
 			srcf.Close()
 			return nil, fmt.Errorf("open dest file: %w", err)
@@ -196,7 +719,20 @@ func writeFilesInSizeRangeToDir(dir string, count int, sizeRange SizeRange) (*Di
 		w, err := io.CopyBuffer(destf, srcf, buf)
 		srcf.Close()
 		destf.Close()
-		if err := os.Remove(destf.Name()); err != nil {
+		fileTime := time.Since(fileStart)
+
+		// Verification reads the copy back before it is removed. Its time
+		// is left out of the result.
+		var verifyErr error
+		if err == nil && verifyWrites {
+			verifyStart := time.Now()
+			verifyErr = verifyCopy(destf.Name(), srcHashes[ii%len(srcHashes)])
+			verifyTime += time.Since(verifyStart)
+		}
+
+		if noCleanup {
+			destFiles = append(destFiles, destf.Name())
+		} else if err := removeTemp(destf.Name()); err != nil {
 			panic(err)
 		}
 
@@ -205,13 +741,23 @@ func writeFilesInSizeRangeToDir(dir string, count int, sizeRange SizeRange) (*Di
 		} else {
 			totalWritten += w
 		}
+		if verifyErr != nil {
+			return nil, verifyErr
+		}
+		if verifyWrites {
+			verified++
+		}
+
+		copyTimes[ii%len(srcFiles)].add(fileTime)
 	}
 
-	since := float32(time.Since(start)) / float32(time.Second)
+	since := float32(time.Since(start)-verifyTime) / float32(time.Second)
 
-	for _, name := range srcFiles {
-		if err := os.Remove(name); err != nil {
-			return nil, fmt.Errorf("remote src files: %w", err)
+	if !noCleanup {
+		for _, name := range srcFiles {
+			if err := removeTemp(name); err != nil {
+				return nil, fmt.Errorf("remote src files: %w", err)
+			}
 		}
 	}
 
@@ -219,5 +765,64 @@ func writeFilesInSizeRangeToDir(dir string, count int, sizeRange SizeRange) (*Di
 		Seconds: since,
 		Count:   count,
 		Bytes:   totalWritten,
+		FinalCV: meanCV(copyTimes),
+
+		VerifiedCount: verified,
 	}, nil
 }
+
+// keepFiles logs the files a run left in dir with BM_NO_CLEANUP set and stops
+// tracking them, so they survive a shutdown. /cleanup still removes them.
+func keepFiles(dir string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	for _, name := range names {
+		keepTemp(name)
+	}
+	log.Printf("BM_NO_CLEANUP: kept %d files in %s: %s", len(names), dir, strings.Join(names, " "))
+}
+
+// hashFile returns the SHA-256 of the content of name.
+func hashFile(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// verifyCopy checks that the file name, a copy just written, has the
+// SHA-256 want. The copy is flushed and evicted from the page cache first,
+// so it is read back from the device rather than from memory. Where that is
+// unsupported the copy is still compared, but may be read from the cache.
+func verifyCopy(name string, want []byte) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("verify dest file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("verify dest file: sync: %w", err)
+	}
+	err = evictPageCache(f)
+	f.Close()
+	if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return fmt.Errorf("verify dest file: evict from page cache: %w", err)
+	}
+
+	got, err := hashFile(name)
+	if err != nil {
+		return fmt.Errorf("verify dest file: %w", err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("verify dest file: SHA-256 %x does not match src %x", got, want)
+	}
+	return nil
+}
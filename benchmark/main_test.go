@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testClassesBody runs every size class with a handful of small files, so a
+// full disk benchmark takes milliseconds.
+const testClassesBody = `{"classes": {
+	"tiny":   {"count": 3, "size_range": {"min": 10, "max": 20}},
+	"small":  {"count": 3, "size_range": {"min": 100, "max": 200}},
+	"medium": {"count": 3, "size_range": {"min": 1000, "max": 2000}},
+	"large":  {"count": 3, "size_range": {"min": 10000, "max": 20000}},
+	"huge":   {"count": 3, "size_range": {"min": 100000, "max": 200000}}
+}}`
+
+// setupTestConfig points both benchmark directories at fresh temp dirs and
+// applies the default configuration, the way main does on startup.
+func setupTestConfig(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("BM_CONFIG_FILE", "")
+	t.Setenv("BM_EPHEMERAL_DIR", t.TempDir())
+	t.Setenv("BM_PERSISTENT_DIR", t.TempDir())
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveRecorder calls h directly with an httptest.ResponseRecorder.
+func serveRecorder(t *testing.T, h http.HandlerFunc, body string) *http.Response {
+	req := httptest.NewRequest("POST", "/?src_count=2", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec.Result()
+}
+
+// serveServer sends the request through a real HTTP server, with the request
+// ID middleware main installs.
+func serveServer(t *testing.T, h http.HandlerFunc, body string) *http.Response {
+	srv := httptest.NewServer(RequestIDMiddleware(h))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Post(srv.URL+"/?src_count=2", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+var diskHandlerTests = []struct {
+	name    string
+	handler http.HandlerFunc
+	dir     *string
+}{
+	{"ephemeral", benchEphemeralDisk, &ephemeralDir},
+	{"persistent", benchPersistentDisk, &persistentDir},
+}
+
+var serveFuncs = []struct {
+	name  string
+	serve func(t *testing.T, h http.HandlerFunc, body string) *http.Response
+}{
+	{"recorder", serveRecorder},
+	{"server", serveServer},
+}
+
+func TestDiskHandlers(t *testing.T) {
+	for _, tt := range diskHandlerTests {
+		for _, sf := range serveFuncs {
+			t.Run(tt.name+"/"+sf.name, func(t *testing.T) {
+				setupTestConfig(t)
+
+				resp := sf.serve(t, tt.handler, testClassesBody)
+
+				if resp.StatusCode != 200 {
+					t.Fatalf("status = %d, want 200", resp.StatusCode)
+				}
+				if ct := resp.Header.Get("content-type"); ct != "application/json" {
+					t.Errorf("content-type = %q, want application/json", ct)
+				}
+
+				var res DiskBenchmarkResult
+				if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+
+				for _, class := range defaultSizeClasses() {
+					rw := res.get(class.Name)
+					if rw == nil {
+						t.Errorf("%s: result is missing", class.Name)
+						continue
+					}
+					if rw.Count != 3 {
+						t.Errorf("%s: Count = %d, want 3", class.Name, rw.Count)
+					}
+				}
+			})
+		}
+	}
+}
+
+// unwritableDir returns a directory path no one can create files in, root
+// included: it lies under a regular file, so every create fails with
+// ENOTDIR.
+func unwritableDir(t *testing.T) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(file, "sub")
+}
+
+func TestDiskHandlersUnwritableDir(t *testing.T) {
+	for _, tt := range diskHandlerTests {
+		for _, sf := range serveFuncs {
+			t.Run(tt.name+"/"+sf.name, func(t *testing.T) {
+				setupTestConfig(t)
+				*tt.dir = unwritableDir(t)
+
+				resp := sf.serve(t, tt.handler, testClassesBody)
+
+				if resp.StatusCode != 500 {
+					t.Fatalf("status = %d, want 500", resp.StatusCode)
+				}
+				if ct := resp.Header.Get("content-type"); ct != "application/json" {
+					t.Errorf("content-type = %q, want application/json", ct)
+				}
+
+				var body map[string]any
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+					t.Fatalf("decode error response: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestNewSizeRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		wantErr  bool
+	}{
+		{"valid", 1, 1024, false},
+		{"max at limit", 1, maxSizeRangeBytes, false},
+		{"min above max", 20, 10, true},
+		{"min equals max", 10, 10, true},
+		{"negative min", -1, 10, true},
+		{"zero min", 0, 10, true},
+		{"max above limit", 1, maxSizeRangeBytes + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSizeRange(tt.min, tt.max)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("NewSizeRange(%d, %d) error = %v, want error %v", tt.min, tt.max, err, tt.wantErr)
+			}
+			if err == nil && got != (SizeRange{tt.min, tt.max}) {
+				t.Errorf("NewSizeRange(%d, %d) = %+v", tt.min, tt.max, got)
+			}
+		})
+	}
+}
+
+func TestWriteFilesInSizeRangeToDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		sizeRange SizeRange
+		srcFiles  int
+		wantBytes int64
+	}{
+		{"zero count", 0, SizeRange{10, 20}, 2, 0},
+		{"one byte files", 5, SizeRange{1, 1}, 1, 5},
+		{"several src files", 4, SizeRange{100, 200}, 2, 100 + 150 + 100 + 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestConfig(t)
+			dir := t.TempDir()
+
+			res, err := writeFilesInSizeRangeToDir(context.Background(), dir, tt.count, tt.sizeRange, tt.srcFiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Count != tt.count || res.Bytes != tt.wantBytes {
+				t.Errorf("got Count %d, Bytes %d, want Count %d, Bytes %d", res.Count, res.Bytes, tt.count, tt.wantBytes)
+			}
+
+			assertEmptyDir(t, dir)
+		})
+	}
+}
+
+// TestWriteFilesInSizeRangeToDirCleanupOnError checks that the source files
+// are removed when the run fails after creating them. Two one byte source
+// files are rejected by validateSizeDistribution.
+func TestWriteFilesInSizeRangeToDirCleanupOnError(t *testing.T) {
+	setupTestConfig(t)
+	dir := t.TempDir()
+
+	if _, err := writeFilesInSizeRangeToDir(context.Background(), dir, 5, SizeRange{1, 1}, 2); err == nil {
+		t.Fatal("expected an error for identical src file sizes")
+	}
+
+	assertEmptyDir(t, dir)
+}
+
+func TestWriteFilesInSizeRangeToDirUnwritable(t *testing.T) {
+	setupTestConfig(t)
+	dir := unwritableDir(t)
+
+	_, err := writeFilesInSizeRangeToDir(context.Background(), dir, 1, SizeRange{10, 20}, 1)
+	if !errors.Is(err, syscall.ENOTDIR) {
+		t.Fatalf("err = %v, want a wrapped ENOTDIR", err)
+	}
+	if !strings.Contains(err.Error(), "create temp file") {
+		t.Errorf("err = %q, want it to name the failed operation", err)
+	}
+}
+
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Errorf("left behind %s", e.Name())
+	}
+}
+
+// TestValidateSizeDistributionMaxSrcFileCount checks that every default size
+// class accepts maxSrcFileCount linearly spaced source files, and that the
+// huge class rejects one more. The files are sparse, so the sizes cost no
+// disk space.
+func TestValidateSizeDistributionMaxSrcFileCount(t *testing.T) {
+	type test struct {
+		class   SizeClass
+		n       int
+		wantErr bool
+	}
+
+	var tests []test
+	for _, class := range defaultSizeClasses() {
+		tests = append(tests, test{class, maxSrcFileCount, false})
+	}
+	tests = append(tests, test{defaultSizeClasses()[4], maxSrcFileCount + 1, true})
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.class.Name, tt.n), func(t *testing.T) {
+			dir := t.TempDir()
+
+			var files []string
+			for i := range tt.n {
+				name := filepath.Join(dir, strconv.Itoa(i))
+				f, err := os.Create(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = f.Truncate(int64(srcFileSize(tt.class.SizeRange, i, tt.n)))
+				f.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, name)
+			}
+
+			err := validateSizeDistribution(files)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("validateSizeDistribution() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestMeanCVComparesCopiesOfSameSource checks that meanCV only compares
+// copies of the same source file: sources of different sizes alone stay below
+// maxCV, where a CV taken over all copies together would not, and an outlier
+// among one source's copies pushes it above.
+func TestMeanCVComparesCopiesOfSameSource(t *testing.T) {
+	setupTestConfig(t)
+
+	perSrc := make([]runningCV, 10)
+	var pooled runningCV
+	for i := range 100 {
+		src := i % len(perSrc)
+		d := time.Duration(src+1) * 100 * time.Microsecond
+		if i%2 == 0 {
+			d += d / 100
+		}
+		perSrc[src].add(d)
+		pooled.add(d)
+	}
+
+	if cv := meanCV(perSrc); cv > maxCV {
+		t.Errorf("meanCV() = %.3f, want at most maxCV %.3f", cv, maxCV)
+	}
+	if cv := pooled.cv(); cv <= maxCV {
+		t.Errorf("pooled CV = %.3f, want above maxCV %.3f", cv, maxCV)
+	}
+
+	perSrc[0].add(time.Second)
+	if cv := meanCV(perSrc); cv <= maxCV {
+		t.Errorf("meanCV() with an outlier = %.3f, want above maxCV %.3f", cv, maxCV)
+	}
+}
+
+// TestMetricsSummaryQuickRunTakesLock checks that a summary without stored
+// results, which falls back to a quick disk run, waits its turn like any
+// other disk benchmark.
+func TestMetricsSummaryQuickRunTakesLock(t *testing.T) {
+	setupTestConfig(t)
+
+	benchmarkMu.Lock()
+	defer benchmarkMu.Unlock()
+
+	rec := httptest.NewRecorder()
+	metricsSummary(rec, httptest.NewRequest("GET", "/metrics/summary", nil))
+
+	if rec.Code != 409 {
+		t.Errorf("status = %d, want 409", rec.Code)
+	}
+}
+
+func TestBenchmarkSuiteRun(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	var suite BenchmarkSuite
+	var ran []string
+	for _, name := range []string{"first", "failing", "last"} {
+		suite.Add(name, func(ctx context.Context) (any, error) {
+			ran = append(ran, name)
+			if name == "failing" {
+				return nil, errFailed
+			}
+			return name + " value", nil
+		})
+	}
+
+	res, err := suite.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(ran, " "); got != "first failing last" {
+		t.Errorf("ran %q, want first failing last", got)
+	}
+	if got := strings.Join(res.Order, " "); got != "first failing last" {
+		t.Errorf("Order = %q, want first failing last", got)
+	}
+	if got := res.Results["failing"].Error; !errors.Is(got, errFailed) {
+		t.Errorf("failing Error = %v, want %v", got, errFailed)
+	}
+	if got := res.Results["last"]; got.Error != nil || got.Value != "last value" {
+		t.Errorf("last = %+v, want its value and no error", got)
+	}
+}
+
+func TestBenchmarkSuiteRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var suite BenchmarkSuite
+	suite.Add("cancels", func(ctx context.Context) (any, error) {
+		cancel()
+		return nil, nil
+	})
+	suite.Add("skipped", func(ctx context.Context) (any, error) {
+		t.Error("benchmark ran after the context was canceled")
+		return nil, nil
+	})
+
+	res, err := suite.Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if got := strings.Join(res.Order, " "); got != "cancels" {
+		t.Errorf("Order = %q, want the results so far", got)
+	}
+}
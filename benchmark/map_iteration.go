@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func benchMapIteration(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		MapIterationResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	size, err := queryInt(r, "size", 1000000, 1, 10000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 10, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if size*count > maxMapIterationEntries {
+		http.Error(w, fmt.Sprintf("query parameters size and count: at most %d entries in total", maxMapIterationEntries), 400)
+		return
+	}
+
+	response.MapIterationResult = *benchmarkMapIteration(size, count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type MapIterationResult struct {
+	Size  int
+	Count int
+	Map   IterationRun
+	Slice IterationRun
+}
+
+// IterationRun counts one pass over all entries as an iteration. GBps is
+// the keys and values visited per second, 16 bytes per entry.
+type IterationRun struct {
+	IterationsPerSec float64
+	GBps             float64
+}
+
+const maxMapIterationEntries = 1000000000
+
+// benchmarkMapIteration fills a map[int64]int64 and a slice of key value
+// pairs with the same size entries and ranges over each count times, summing
+// the values.
+func benchmarkMapIteration(size int, count int) *MapIterationResult {
+	m := make(map[int64]int64, size)
+	s := make([]struct{ K, V int64 }, size)
+	for i := range size {
+		m[int64(i)] = int64(i) * 3
+		s[i].K, s[i].V = int64(i), int64(i)*3
+	}
+
+	res := &MapIterationResult{Size: size, Count: count}
+	gb := float64(size) * 16 * float64(count) / (1 << 30)
+
+	var sum int64
+	start := time.Now()
+	for range count {
+		for _, v := range m {
+			sum += v
+		}
+	}
+	since := time.Since(start)
+	res.Map = IterationRun{IterationsPerSec: float64(count) / since.Seconds(), GBps: gb / since.Seconds()}
+
+	start = time.Now()
+	for range count {
+		for _, e := range s {
+			sum += e.V
+		}
+	}
+	since = time.Since(start)
+	res.Slice = IterationRun{IterationsPerSec: float64(count) / since.Seconds(), GBps: gb / since.Seconds()}
+
+	keep(sum)
+
+	return res
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// metaHandler identifies this agent to the coordinator.
+func metaHandler(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		Hostname  string
+		CloudMeta CloudMeta
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		fmt.Println(requestIDFromContext(r.Context()), err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	writePrettyJSON(w, 200, Response{hostname, cloudMeta}, queryPretty(r))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func benchMiddlewareChain(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		MiddlewareChainResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	depth, err := queryInt(r, "depth", 10, 1, 10000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	requests, err := queryInt(r, "requests", 1000000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.MiddlewareChainResult = *benchmarkMiddlewareChain(depth, requests)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// MiddlewareChainResult has the cost of a request to the bare handler and to
+// the handler behind depth middleware. OverheadNsPerLayer is the difference
+// divided by depth.
+type MiddlewareChainResult struct {
+	Depth              int
+	Requests           int
+	BaselineNsPerReq   float64
+	ChainNsPerReq      float64
+	OverheadNsPerLayer float64
+}
+
+// identityMiddleware passes every request on unchanged, so a chain of them
+// measures nothing but the cost of the layers.
+func identityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+	})
+}
+
+// benchmarkMiddlewareChain serves requests requests to a no-op handler, and
+// then to the same handler wrapped in depth identity middleware. Nothing is
+// ever written to the response, so one httptest.ResponseRecorder serves all
+// of them and its allocation does not drown out the layers.
+func benchmarkMiddlewareChain(depth int, requests int) *MiddlewareChainResult {
+	var handler http.Handler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	chain := handler
+	for range depth {
+		chain = identityMiddleware(chain)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+
+	serve := func(h http.Handler) float64 {
+		start := time.Now()
+		for range requests {
+			h.ServeHTTP(rec, req)
+		}
+		return float64(time.Since(start).Nanoseconds()) / float64(requests)
+	}
+
+	res := &MiddlewareChainResult{
+		Depth:            depth,
+		Requests:         requests,
+		BaselineNsPerReq: serve(handler),
+		ChainNsPerReq:    serve(chain),
+	}
+	res.OverheadNsPerLayer = (res.ChainNsPerReq - res.BaselineNsPerReq) / float64(depth)
+
+	return res
+}
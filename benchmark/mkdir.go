@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func benchMkdirAll(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		MkdirAllResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	depth, err := queryInt(r, "depth", 8, 1, 64)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkMkdirAll(dir, depth, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.MkdirAllResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type MkdirAllResult struct {
+	Depth            int
+	Count            int
+	MkdirSeconds     float32
+	PathsPerSec      float64
+	RemoveAllSeconds float32
+}
+
+// benchmarkMkdirAll creates count paths of depth directories each under a
+// fresh temp dir, then removes the whole tree. Every component is a new UUID
+// so no lookup hits an already cached directory entry.
+func benchmarkMkdirAll(dir string, depth int, count int) (*MkdirAllResult, error) {
+	root, err := mkdirTemp(dir, "mkdir_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(root)
+
+	paths := make([]string, count)
+	for i := range paths {
+		components := []string{root}
+		for range depth {
+			id, err := newUUIDv4()
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, id)
+		}
+		paths[i] = filepath.Join(components...)
+	}
+
+	start := time.Now()
+	for _, path := range paths {
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return nil, fmt.Errorf("mkdir all: %w", err)
+		}
+	}
+	mkdirTime := time.Since(start)
+
+	start = time.Now()
+	if err := removeTemp(root); err != nil {
+		return nil, fmt.Errorf("remove all: %w", err)
+	}
+	removeTime := time.Since(start)
+
+	return &MkdirAllResult{
+		Depth:            depth,
+		Count:            count,
+		MkdirSeconds:     float32(mkdirTime) / float32(time.Second),
+		PathsPerSec:      float64(count) / mkdirTime.Seconds(),
+		RemoveAllSeconds: float32(removeTime) / float32(time.Second),
+	}, nil
+}
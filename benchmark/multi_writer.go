@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+func benchMultiWriter(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		MultiWriterResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	sinks, err := queryInt(r, "sinks", 4, 1, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeKB, err := queryInt(r, "size_kb", 4, 1, 16*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkMultiWriter(sinks, sizeKB*1024, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.MultiWriterResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// MultiWriterResult counts every buffer once, however many sinks it goes to.
+// OverheadFactor is how many times faster the single io.Discard was.
+type MultiWriterResult struct {
+	Sinks          int
+	Size           int
+	Count          int
+	SingleMBps     float64
+	MultiMBps      float64
+	OverheadFactor float64
+}
+
+// benchmarkMultiWriter writes count buffers of size bytes to io.Discard and
+// then to an io.MultiWriter of sinks io.Discard writers.
+func benchmarkMultiWriter(sinks int, size int, count int) (*MultiWriterResult, error) {
+	buf := make([]byte, size)
+	if err := fillContent(buf); err != nil {
+		return nil, err
+	}
+
+	writers := make([]io.Writer, sinks)
+	for i := range writers {
+		writers[i] = io.Discard
+	}
+	multi := io.MultiWriter(writers...)
+
+	mb := float64(size) * float64(count) / (1 << 20)
+
+	start := time.Now()
+	for range count {
+		io.Discard.Write(buf)
+	}
+	single := time.Since(start)
+
+	start = time.Now()
+	for range count {
+		multi.Write(buf)
+	}
+	fanOut := time.Since(start)
+
+	return &MultiWriterResult{
+		Sinks:          sinks,
+		Size:           size,
+		Count:          count,
+		SingleMBps:     mb / single.Seconds(),
+		MultiMBps:      mb / fanOut.Seconds(),
+		OverheadFactor: fanOut.Seconds() / single.Seconds(),
+	}, nil
+}
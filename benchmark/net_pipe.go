@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func benchNetPipe(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		NetPipeResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	msgKB, err := queryInt(r, "msg_kb", 4, 1, 16*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 10000, 1, 10000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkNetPipe(msgKB*1024, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.NetPipeResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type NetPipeResult struct {
+	MessageSize int
+	Count       int
+	Pipe        ConnRun
+	UnixSocket  ConnRun
+	TCPLoopback ConnRun
+}
+
+// ConnRun is the echo benchmark over one kind of connection. Throughput
+// counts each message once, not once per direction.
+type ConnRun struct {
+	ThroughputMBps float64
+	RoundTrip      LatencyStats
+}
+
+// benchmarkNetPipe sends count messages of messageSize bytes over a net.Pipe
+// and has the other end echo each one back. The same exchange is then run
+// over a Unix socket and TCP on the loopback interface, which go through the
+// kernel.
+func benchmarkNetPipe(messageSize int, count int) (*NetPipeResult, error) {
+	res := &NetPipeResult{MessageSize: messageSize, Count: count}
+
+	client, server := net.Pipe()
+	run, err := runEcho(client, server, messageSize, count)
+	if err != nil {
+		return nil, &StepError{"pipe", err}
+	}
+	res.Pipe = *run
+
+	dir, err := mkdirTemp(os.TempDir(), "net_pipe_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(dir)
+
+	if run, err = runEchoOver("unix", filepath.Join(dir, "echo.sock"), messageSize, count); err != nil {
+		return nil, &StepError{"unix socket", err}
+	}
+	res.UnixSocket = *run
+
+	if run, err = runEchoOver("tcp", "127.0.0.1:0", messageSize, count); err != nil {
+		return nil, &StepError{"tcp loopback", err}
+	}
+	res.TCPLoopback = *run
+
+	return res, nil
+}
+
+// runEchoOver listens on address, connects to it and runs runEcho over the
+// connection.
+func runEchoOver(network, address string, messageSize int, count int) (*ConnRun, error) {
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err := net.Dial(network, ln.Addr().String())
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	server, ok := <-accepted
+	if !ok {
+		client.Close()
+		return nil, fmt.Errorf("accept failed")
+	}
+
+	return runEcho(client, server, messageSize, count)
+}
+
+// runEcho has server echo everything it reads while client sends count
+// messages and waits for each to come back. Both connections are closed on
+// return.
+func runEcho(client, server net.Conn, messageSize int, count int) (*ConnRun, error) {
+	defer client.Close()
+	defer server.Close()
+
+	go io.Copy(server, server)
+
+	msg := make([]byte, messageSize)
+	if err := fillContent(msg); err != nil {
+		return nil, err
+	}
+	echo := make([]byte, messageSize)
+
+	samples := make([]time.Duration, 0, count)
+
+	start := time.Now()
+
+	for range count {
+		sent := time.Now()
+
+		// net.Pipe is unbuffered, so the echo has to be read while the
+		// message is still being written.
+		errc := make(chan error, 1)
+		go func() {
+			_, err := client.Write(msg)
+			errc <- err
+		}()
+		if _, err := io.ReadFull(client, echo); err != nil {
+			return nil, fmt.Errorf("read echo: %w", err)
+		}
+		if err := <-errc; err != nil {
+			return nil, fmt.Errorf("write message: %w", err)
+		}
+
+		samples = append(samples, time.Since(sent))
+	}
+
+	since := time.Since(start)
+
+	return &ConnRun{
+		ThroughputMBps: float64(count) * float64(messageSize) / (1 << 20) / since.Seconds(),
+		RoundTrip:      latencyStats(samples),
+	}, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+func benchNetwork(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		NetworkBenchmarkResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	count, err := queryInt(r, "count", 5, 1, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.NetworkBenchmarkResult = *benchmarkNetwork(networkTargets, networkTargetsV6, count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// NetworkBenchmarkResult holds the TCP connect latency to each configured
+// target, keyed by host:port. Targets that could not be reached are listed in
+// Errors instead. IPv6Supported is only probed when IPv6 targets are
+// configured.
+type NetworkBenchmarkResult struct {
+	IPv4Latency   map[string]LatencyStats
+	IPv6Latency   map[string]LatencyStats
+	IPv6Supported bool
+	Errors        map[string]string `json:",omitempty"`
+}
+
+var networkTargets, networkTargetsV6 []string
+
+const (
+	networkDialTimeout = 5 * time.Second
+
+	// ipv6Probe is dialled to find out whether the host can reach the IPv6
+	// internet at all. It is Google Public DNS.
+	ipv6Probe = "[2001:4860:4860::8888]:53"
+)
+
+// benchmarkNetwork opens count TCP connections to every target and times how
+// long each connect takes. IPv6 targets are skipped when ipv6Probe cannot be
+// reached.
+func benchmarkNetwork(targets, targetsV6 []string, count int) *NetworkBenchmarkResult {
+	res := &NetworkBenchmarkResult{
+		IPv4Latency: map[string]LatencyStats{},
+		IPv6Latency: map[string]LatencyStats{},
+		Errors:      map[string]string{},
+	}
+
+	for _, target := range targets {
+		if stats, err := connectLatency("tcp4", target, count); err != nil {
+			res.Errors[target] = err.Error()
+		} else {
+			res.IPv4Latency[target] = stats
+		}
+	}
+
+	if len(targetsV6) == 0 {
+		return res
+	}
+
+	if conn, err := net.DialTimeout("tcp6", ipv6Probe, networkDialTimeout); err == nil {
+		conn.Close()
+		res.IPv6Supported = true
+	} else {
+		return res
+	}
+
+	for _, target := range targetsV6 {
+		if stats, err := connectLatency("tcp6", target, count); err != nil {
+			res.Errors[target] = err.Error()
+		} else {
+			res.IPv6Latency[target] = stats
+		}
+	}
+
+	return res
+}
+
+func connectLatency(network, target string, count int) (LatencyStats, error) {
+	samples := make([]time.Duration, 0, count)
+
+	for range count {
+		start := time.Now()
+		conn, err := net.DialTimeout(network, target, networkDialTimeout)
+		if err != nil {
+			return LatencyStats{}, err
+		}
+		samples = append(samples, time.Since(start))
+		conn.Close()
+	}
+
+	return latencyStats(samples), nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+func benchPageCache(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		PageCacheResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeMB, err := queryInt(r, "size_mb", 256, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkPageCacheEffect(dir, sizeMB)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.PageCacheResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type PageCacheResult struct {
+	FileSizeMB   int
+	WarmReadGBps float64
+	ColdReadGBps float64
+}
+
+// benchmarkPageCacheEffect writes a file of fileSizeMB and reads it back
+// twice: once straight away, while it is still in the page cache, and once
+// after asking the kernel to drop its pages with FADV_DONTNEED. The file is
+// synced first, since dirty pages are not evicted.
+func benchmarkPageCacheEffect(dir string, fileSizeMB int) (*PageCacheResult, error) {
+	chunk := make([]byte, 1024*1024)
+	if err := fillContent(chunk); err != nil {
+		return nil, err
+	}
+
+	f, err := createTemp(dir, "page_cache_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	for range fileSizeMB {
+		if _, err := f.Write(chunk); err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("sync temp file: %w", err)
+	}
+
+	res := &PageCacheResult{FileSizeMB: fileSizeMB}
+
+	warm, err := timeFileRead(f, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := evictPageCache(f); err != nil {
+		return nil, fmt.Errorf("evict temp file from page cache: %w", err)
+	}
+
+	cold, err := timeFileRead(f, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	gb := float64(fileSizeMB) / 1024
+	res.WarmReadGBps = gb / warm.Seconds()
+	res.ColdReadGBps = gb / cold.Seconds()
+
+	return res, nil
+}
+
+// timeFileRead reads f from the start to the end using buf and returns how
+// long it took.
+func timeFileRead(f *os.File, buf []byte) (time.Duration, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seek temp file: %w", err)
+	}
+
+	start := time.Now()
+
+	for {
+		_, err := f.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read temp file: %w", err)
+		}
+	}
+
+	return time.Since(start), nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func benchPageFaults(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		PageFaultResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeMB, err := queryInt(r, "size_mb", 256, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkPageFaultRate(dir, int64(sizeMB)*1024*1024)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.PageFaultResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type PageFaultResult struct {
+	FileSize    int64
+	Pages       int64
+	MajorFaults int64
+	MinorFaults int64
+	ReadGBps    float64
+}
+
+// benchmarkPageFaultRate writes a file of fileSize, evicts it from the page
+// cache and reads it sequentially through a memory mapping, touching one byte
+// per page. The faults are counted with getrusage for the reading thread only.
+// A plain read(2) would not show up, since the kernel copies the data without
+// faulting in the process.
+func benchmarkPageFaultRate(dir string, fileSize int64) (*PageFaultResult, error) {
+	f, err := createTemp(dir, "page_faults_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	chunk := make([]byte, 1024*1024)
+	if err := fillContent(chunk); err != nil {
+		return nil, err
+	}
+
+	for written := int64(0); written < fileSize; {
+		n, err := f.Write(chunk[:min(int64(len(chunk)), fileSize-written)])
+		if err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+		written += int64(n)
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := evictPageCache(f); err != nil {
+		return nil, fmt.Errorf("evict temp file from page cache: %w", err)
+	}
+
+	data, err := unix.Mmap(int(f.Fd()), 0, int(fileSize), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap temp file: %w", err)
+	}
+	defer unix.Munmap(data)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var before, after unix.Rusage
+	if err := threadRusage(&before); err != nil {
+		return nil, fmt.Errorf("getrusage: %w", err)
+	}
+
+	pageSize := os.Getpagesize()
+	var sum byte
+
+	start := time.Now()
+
+	for off := 0; off < len(data); off += pageSize {
+		sum += data[off]
+	}
+
+	since := time.Since(start)
+
+	if err := threadRusage(&after); err != nil {
+		return nil, fmt.Errorf("getrusage: %w", err)
+	}
+	runtime.KeepAlive(sum)
+
+	return &PageFaultResult{
+		FileSize:    fileSize,
+		Pages:       (fileSize + int64(pageSize) - 1) / int64(pageSize),
+		MajorFaults: after.Majflt - before.Majflt,
+		MinorFaults: after.Minflt - before.Minflt,
+		ReadGBps:    float64(fileSize) / (1 << 30) / since.Seconds(),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// queryInt returns the integer query parameter name, or def when the parameter
+// is absent. Values outside [min, max] are rejected.
+func queryInt(r *http.Request, name string, def, min, max int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("query parameter %s: %w", name, err)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("query parameter %s: must be between %d and %d", name, min, max)
+	}
+
+	return n, nil
+}
+
+// queryDir returns the benchmark directory selected by the disk query
+// parameter, which is either "ephemeral" (the default) or "persistent".
+func queryDir(r *http.Request) (string, error) {
+	switch disk := r.URL.Query().Get("disk"); disk {
+	case "", "ephemeral":
+		return ephemeralDir, nil
+	case "persistent":
+		return persistentDir, nil
+	default:
+		return "", fmt.Errorf("query parameter disk: unknown disk %q", disk)
+	}
+}
+
+// queryFloat is like queryInt for floating point parameters.
+func queryFloat(r *http.Request, name string, def, min, max float64) (float64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("query parameter %s: %w", name, err)
+	}
+	if !(n >= min && n <= max) {
+		return 0, fmt.Errorf("query parameter %s: must be between %g and %g", name, min, max)
+	}
+
+	return n, nil
+}
+
+// queryClasses returns the size classes named in the comma separated classes
+// query parameter, or nil when the parameter is absent. Unknown names are
+// all reported at once.
+func queryClasses(r *http.Request) (map[string]bool, error) {
+	v := r.URL.Query().Get("classes")
+	if v == "" {
+		return nil, nil
+	}
+
+	known := map[string]bool{}
+	for _, class := range defaultSizeClasses() {
+		known[class.Name] = true
+	}
+
+	selected := map[string]bool{}
+	var unknown []string
+	for _, name := range strings.Split(v, ",") {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+		selected[name] = true
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("query parameter classes: unknown size classes %s", strings.Join(unknown, ", "))
+	}
+
+	return selected, nil
+}
+
+// queryPretty reports whether the client asked for indented JSON with
+// pretty=true.
+func queryPretty(r *http.Request) bool {
+	return r.URL.Query().Get("pretty") == "true"
+}
@@ -0,0 +1,41 @@
+// Package plugin defines the contract between the benchmark server and
+// benchmarks loaded at runtime from BM_PLUGIN_DIR.
+//
+// A plugin is a Go package main built with -buildmode=plugin that exports
+//
+//	func Register(mux *http.ServeMux, meta plugin.BenchmarkMeta)
+//
+// The server calls Register once at startup, and the plugin adds its
+// endpoints to mux. Go only loads a plugin that was built with the same Go
+// version, the same build flags and the same versions of every shared
+// package, this one included, as the server. In practice that means building
+// plugins from this module with the same toolchain as the server binary.
+package plugin
+
+import "net/http"
+
+// BenchmarkMeta describes the environment a plugin runs in.
+type BenchmarkMeta struct {
+	EphemeralDir  string
+	PersistentDir string
+	InstanceType  string
+	Region        string
+
+	// Exclusive wraps a handler so it does not run concurrently with any
+	// other benchmark. Plugins should wrap their benchmark endpoints with it.
+	Exclusive func(http.HandlerFunc) http.HandlerFunc
+}
+
+// BenchmarkPlugin is implemented by anything that can add benchmark
+// endpoints to the server.
+type BenchmarkPlugin interface {
+	Register(mux *http.ServeMux, meta BenchmarkMeta)
+}
+
+// RegisterFunc adapts a plugin's exported Register function to
+// BenchmarkPlugin.
+type RegisterFunc func(mux *http.ServeMux, meta BenchmarkMeta)
+
+func (f RegisterFunc) Register(mux *http.ServeMux, meta BenchmarkMeta) {
+	f(mux, meta)
+}
@@ -0,0 +1,326 @@
+// Package report renders benchmark results as a PDF for sharing.
+//
+// The input is the per-agent output of the coordinator. Every endpoint is
+// assigned to a category, and each category starts on a new page with one
+// table per endpoint. The tables list every numeric field of the endpoint's
+// JSON response for every agent, next to a bar scaled to the largest value of
+// that field, so agents can be compared at a glance.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// CloudMeta describes the machine an agent runs on, as served by /meta.
+type CloudMeta struct {
+	InstanceType string
+	Region       string
+}
+
+// AggregatedResult is everything collected from one agent. Results and
+// Errors are keyed by endpoint path.
+type AggregatedResult struct {
+	Agent     string
+	Hostname  string
+	CloudMeta CloudMeta
+	Results   map[string]json.RawMessage
+	Errors    map[string]string `json:",omitempty"`
+}
+
+// Categories are the report pages, in order. Endpoints not listed in
+// endpointCategories go on an extra "Other" page, which is left out when it
+// would be empty.
+var Categories = []string{"Disk", "CPU", "Memory", "Network"}
+
+var endpointCategories = map[string]string{
+	"/ephemeral-disk":       "Disk",
+	"/persistent-disk":      "Disk",
+	"/incremental-write":    "Disk",
+	"/read-after-write":     "Disk",
+	"/truncate":             "Disk",
+	"/file-lock":            "Disk",
+	"/mkdir":                "Disk",
+	"/page-cache":           "Disk",
+	"/temp-dir-compare":     "Disk",
+	"/fd-limit":             "Disk",
+	"/random-read":          "Disk",
+	"/concurrent-stat-read": "Disk",
+	"/prealloc-write":       "Disk",
+	"/page-faults":          "Disk",
+	"/line-read":            "Disk",
+	"/flock-vs-mutex":       "Disk",
+	"/sqlite-conn":          "Disk",
+	"/buffered-read":        "Disk",
+	"/stat-variants":        "Disk",
+	"/glob":                 "Disk",
+	"/json-stream":          "Disk",
+	"/concurrent-rw":        "Disk",
+	"/file-server":          "Disk",
+	"/cross-rename":         "Disk",
+	"/readonly-fs":          "Disk",
+	"/serialize-disk":       "Disk",
+	"/logging":              "Disk",
+
+	"/crand-speed":      "CPU",
+	"/context-overhead": "CPU",
+	"/serialization":    "CPU",
+	"/binary-encoding":  "CPU",
+	"/strconv":          "CPU",
+	"/time-now":         "CPU",
+	"/rand-throughput":  "CPU",
+	"/rwmutex":          "CPU",
+	"/sync-once":        "CPU",
+	"/url-parse":        "CPU",
+	"/context-deadline": "CPU",
+	"/string-build":     "CPU",
+	"/csv":              "CPU",
+	"/timer-accuracy":   "CPU",
+	"/fmt-fprintf":      "CPU",
+	"/big-int":          "CPU",
+	"/multi-writer":     "CPU",
+	"/deflate":          "CPU",
+	"/exec-spawn":       "CPU",
+
+	"/allocator":     "Memory",
+	"/sync-pool":     "Memory",
+	"/map-iteration": "Memory",
+
+	"/http-client-pool": "Network",
+	"/websocket":        "Network",
+	"/net-pipe":         "Network",
+	"/network":          "Network",
+	"/http-dispatch":    "Network",
+	"/http-parsing":     "Network",
+	"/response-write":   "Network",
+	"/dial-latency":     "Network",
+	"/reverse-proxy":    "Network",
+	"/middleware-chain": "Network",
+}
+
+// Category returns the report page for an endpoint path. Query parameters
+// are ignored.
+func Category(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	if c, ok := endpointCategories[path]; ok {
+		return c
+	}
+	return "Other"
+}
+
+// ReportGenerator renders AggregatedResults as a PDF. The zero value is
+// usable; Title defaults to "Benchmark report" and GeneratedAt to the time
+// Generate is called.
+type ReportGenerator struct {
+	Title       string
+	GeneratedAt time.Time
+}
+
+// barWidth is the length in characters of a full bar.
+const barWidth = 25
+
+// Table column widths in mm. They add up to the width of an A4 page less the
+// default 10 mm margins.
+const (
+	colAgent  = 50
+	colMetric = 55
+	colValue  = 30
+	colBar    = 55
+)
+
+// Generate writes the PDF report for results to w.
+func (g *ReportGenerator) Generate(w io.Writer, results []AggregatedResult) error {
+	title := g.Title
+	if title == "" {
+		title = "Benchmark report"
+	}
+	generatedAt := g.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
+	// endpoints[category] lists the endpoint paths of the category in
+	// sorted order.
+	endpoints := map[string][]string{}
+	for _, res := range results {
+		for path := range res.Results {
+			c := Category(path)
+			if !slices.Contains(endpoints[c], path) {
+				endpoints[c] = append(endpoints[c], path)
+			}
+		}
+	}
+	for _, paths := range endpoints {
+		slices.Sort(paths)
+	}
+
+	categories := Categories
+	if len(endpoints["Other"]) > 0 {
+		categories = append(slices.Clone(categories), "Other")
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for _, category := range categories {
+		pdf.AddPage()
+
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.CellFormat(0, 10, tr(title+": "+category), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 5, "Generated "+generatedAt.UTC().Format(time.RFC3339), "", 1, "L", false, 0, "")
+		pdf.Ln(4)
+
+		if len(endpoints[category]) == 0 {
+			pdf.SetFont("Helvetica", "I", 11)
+			pdf.CellFormat(0, 8, "No results in this category.", "", 1, "L", false, 0, "")
+			continue
+		}
+
+		for _, path := range endpoints[category] {
+			rows, err := endpointRows(results, path)
+			if err != nil {
+				return err
+			}
+			writeTable(pdf, tr, path, rows)
+		}
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("write pdf: %w", err)
+	}
+	return nil
+}
+
+type row struct {
+	agent  string
+	metric string
+	value  float64
+	bar    string
+}
+
+// endpointRows returns a row per numeric field of the response of path for
+// every agent that has one, grouped by field so the agents sit next to each
+// other.
+func endpointRows(results []AggregatedResult, path string) ([]row, error) {
+	var rows []row
+	maxValue := map[string]float64{}
+
+	for _, res := range results {
+		b, ok := res.Results[path]
+		if !ok {
+			continue
+		}
+
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("decode %s result of %s: %w", path, res.Agent, err)
+		}
+
+		// Agents on different hosts can share a hostname, so the host is
+		// always shown.
+		agent := res.Agent
+		if res.Hostname != "" {
+			agent += " (" + res.Hostname + ")"
+		}
+
+		flatten("", v, func(metric string, value float64) {
+			rows = append(rows, row{agent: agent, metric: metric, value: value})
+			maxValue[metric] = max(maxValue[metric], math.Abs(value))
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b row) int { return strings.Compare(a.metric, b.metric) })
+
+	for i, r := range rows {
+		n := 0
+		if m := maxValue[r.metric]; m > 0 {
+			n = int(math.Round(math.Abs(r.value) / m * barWidth))
+		}
+		rows[i].bar = strings.Repeat("#", n) + strings.Repeat(".", barWidth-n)
+	}
+
+	return rows, nil
+}
+
+// flatten calls fn for every number in v, named by its dot-separated path.
+// Map keys are visited in sorted order. RequestID and other strings, and
+// booleans, are skipped.
+func flatten(prefix string, v any, fn func(metric string, value float64)) {
+	switch v := v.(type) {
+	case float64:
+		fn(prefix, v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			flatten(join(prefix, k), v[k], fn)
+		}
+	case []any:
+		for i, e := range v {
+			flatten(join(prefix, fmt.Sprint(i)), e, fn)
+		}
+	}
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func writeTable(pdf *fpdf.Fpdf, tr func(string) string, path string, rows []row) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, tr(path), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 8)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(colAgent, 6, "Agent", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(colMetric, 6, "Metric", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(colValue, 6, "Value", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(colBar, 6, "", "1", 1, "L", true, 0, "")
+
+	for _, r := range rows {
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(colAgent, 5, fit(pdf, tr(r.agent), colAgent), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colMetric, 5, fit(pdf, tr(r.metric), colMetric), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colValue, 5, formatValue(r.value), "1", 0, "R", false, 0, "")
+		pdf.SetFont("Courier", "", 8)
+		pdf.CellFormat(colBar, 5, r.bar, "1", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(4)
+}
+
+// fit shortens s with an ellipsis until it fits a cell of width mm in the
+// current font.
+func fit(pdf *fpdf.Fpdf, s string, width float64) string {
+	const padding = 2
+	if pdf.GetStringWidth(s) <= width-padding {
+		return s
+	}
+	for len(s) > 0 && pdf.GetStringWidth(s+"...") > width-padding {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.3f", v)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"plugin"
+
+	bmplugin "benchmark/pkg/plugin"
+)
+
+// loadPlugins opens every .so file in dir (BM_PLUGIN_DIR) and lets it register
+// its endpoints on mux. It does nothing when dir is empty.
+func loadPlugins(mux *http.ServeMux, dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return fmt.Errorf("list plugins: %w", err)
+	}
+
+	meta := bmplugin.BenchmarkMeta{
+		EphemeralDir:  ephemeralDir,
+		PersistentDir: persistentDir,
+		InstanceType:  cloudMeta.InstanceType,
+		Region:        cloudMeta.Region,
+		Exclusive:     exclusive,
+	}
+
+	for _, path := range paths {
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("open plugin %s: %w", path, err)
+		}
+
+		sym, err := p.Lookup("Register")
+		if err != nil {
+			return fmt.Errorf("plugin %s: %w", path, err)
+		}
+
+		register, ok := sym.(func(*http.ServeMux, bmplugin.BenchmarkMeta))
+		if !ok {
+			return fmt.Errorf("plugin %s: Register has type %T, want func(*http.ServeMux, plugin.BenchmarkMeta)", path, sym)
+		}
+
+		var bp bmplugin.BenchmarkPlugin = bmplugin.RegisterFunc(register)
+		bp.Register(mux, meta)
+
+		log.Println("loaded plugin", path)
+	}
+
+	return nil
+}
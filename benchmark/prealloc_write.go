@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func benchPreAllocWrite(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		PreAllocWriteResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeMB, err := queryInt(r, "size_mb", 1024, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkPreAllocWrite(dir, int64(sizeMB)*1024*1024)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.PreAllocWriteResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// PreAllocWriteResult compares writing a file into space reserved with
+// fallocate against letting the filesystem allocate it as the writes come in.
+// PreAllocSpeedup is PreAllocGBps divided by NoPreAllocGBps.
+type PreAllocWriteResult struct {
+	FileSize        int64
+	PreAllocGBps    float64
+	NoPreAllocGBps  float64
+	PreAllocSpeedup float64
+}
+
+// benchmarkPreAllocWrite writes two files of fileSize sequentially, the first
+// after reserving its blocks with fallocate and FALLOC_FL_KEEP_SIZE, the second
+// without. Both timings include the fallocate call, if any, and the final
+// fsync.
+func benchmarkPreAllocWrite(dir string, fileSize int64) (*PreAllocWriteResult, error) {
+	chunk := make([]byte, 1024*1024)
+	if err := fillContent(chunk); err != nil {
+		return nil, err
+	}
+
+	prealloc, err := timeSequentialWrite(dir, fileSize, chunk, true)
+	if err != nil {
+		return nil, &StepError{"prealloc", err}
+	}
+
+	noPrealloc, err := timeSequentialWrite(dir, fileSize, chunk, false)
+	if err != nil {
+		return nil, &StepError{"no prealloc", err}
+	}
+
+	gb := float64(fileSize) / (1 << 30)
+
+	return &PreAllocWriteResult{
+		FileSize:        fileSize,
+		PreAllocGBps:    gb / prealloc.Seconds(),
+		NoPreAllocGBps:  gb / noPrealloc.Seconds(),
+		PreAllocSpeedup: noPrealloc.Seconds() / prealloc.Seconds(),
+	}, nil
+}
+
+func timeSequentialWrite(dir string, fileSize int64, chunk []byte, prealloc bool) (time.Duration, error) {
+	f, err := createTemp(dir, "prealloc_write_*")
+	if err != nil {
+		return 0, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	start := time.Now()
+
+	if prealloc {
+		if err := preallocate(f, fileSize); err != nil {
+			return 0, fmt.Errorf("fallocate temp file: %w", err)
+		}
+	}
+
+	for written := int64(0); written < fileSize; {
+		n, err := f.Write(chunk[:min(int64(len(chunk)), fileSize-written)])
+		if err != nil {
+			return 0, fmt.Errorf("write to temp file: %w", err)
+		}
+		written += int64(n)
+	}
+
+	if err := f.Sync(); err != nil {
+		return 0, fmt.Errorf("sync temp file: %w", err)
+	}
+
+	return time.Since(start), nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushgatewayExporter pushes disk benchmark results to a Prometheus
+// Pushgateway. Each disk type is pushed as its own group so a run on one disk
+// does not replace the metrics of the other.
+type PushgatewayExporter struct {
+	url string
+}
+
+// newPushgatewayExporter returns nil unless url is set.
+func newPushgatewayExporter(url string) *PushgatewayExporter {
+	if url == "" {
+		return nil
+	}
+
+	return &PushgatewayExporter{url}
+}
+
+// Export pushes res in the background so the HTTP response is not held up by
+// the Pushgateway. It is a no-op on a nil exporter.
+func (e *PushgatewayExporter) Export(diskType string, res DiskBenchmarkResult) {
+	if e == nil {
+		return
+	}
+
+	labels := prometheus.Labels{
+		"instance_type": cloudMeta.InstanceType,
+		"region":        cloudMeta.Region,
+	}
+
+	seconds := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_seconds",
+		Help:        "Time taken to copy all files of a size class.",
+		ConstLabels: labels,
+	}, []string{"class"})
+	files := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_files",
+		Help:        "Number of files copied for a size class.",
+		ConstLabels: labels,
+	}, []string{"class"})
+	bytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_bytes",
+		Help:        "Number of bytes copied for a size class.",
+		ConstLabels: labels,
+	}, []string{"class"})
+	throughput := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_throughput_mbps",
+		Help:        "MiB copied per second for a size class.",
+		ConstLabels: labels,
+	}, []string{"class"})
+	iops := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_iops",
+		Help:        "Files copied per second for a size class.",
+		ConstLabels: labels,
+	}, []string{"class"})
+	retries := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_retries",
+		Help:        "Times a size class was rerun because its copy times varied too much.",
+		ConstLabels: labels,
+	}, []string{"class"})
+	finalCV := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_final_cv",
+		Help:        "Coefficient of variation of the copy times of the kept run of a size class.",
+		ConstLabels: labels,
+	}, []string{"class"})
+	verified := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "disk_benchmark_verified_files",
+		Help:        "Number of copies read back and verified for a size class.",
+		ConstLabels: labels,
+	}, []string{"class"})
+
+	for _, class := range defaultSizeClasses() {
+		rw := res.get(class.Name)
+		if rw == nil {
+			continue
+		}
+		seconds.WithLabelValues(class.Name).Set(float64(rw.Seconds))
+		files.WithLabelValues(class.Name).Set(float64(rw.Count))
+		bytes.WithLabelValues(class.Name).Set(float64(rw.Bytes))
+		throughput.WithLabelValues(class.Name).Set(rw.ThroughputMBps())
+		iops.WithLabelValues(class.Name).Set(rw.IOPS())
+		retries.WithLabelValues(class.Name).Set(float64(rw.Retries))
+		finalCV.WithLabelValues(class.Name).Set(rw.FinalCV)
+		verified.WithLabelValues(class.Name).Set(float64(rw.VerifiedCount))
+	}
+
+	pusher := push.New(e.url, "disk_benchmark").
+		Grouping("disk_type", diskType).
+		Collector(seconds).
+		Collector(files).
+		Collector(bytes).
+		Collector(throughput).
+		Collector(iops).
+		Collector(retries).
+		Collector(finalCV).
+		Collector(verified)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := pusher.PushContext(ctx); err != nil {
+			fmt.Println(fmt.Errorf("pushgateway export: %w", err))
+		}
+	}()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+func benchRandomRead(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		RandomReadResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	fileMB, err := queryInt(r, "file_mb", 1024, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	readKB, err := queryInt(r, "read_kb", 4, 1, 16*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 10000, 1, 10000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if readKB > fileMB*1024 {
+		http.Error(w, "query parameter read_kb: must not be larger than file_mb", 400)
+		return
+	}
+
+	res, err := benchmarkRandomRead(dir, int64(fileMB)*1024*1024, readKB*1024, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.RandomReadResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type RandomReadResult struct {
+	FileSize int64
+	ReadSize int
+	Count    int
+	Seconds  float32
+	IOPS     float64
+	ReadGBps float64
+	Latency  LatencyStats
+}
+
+// benchmarkRandomRead writes a file of fileSize, drops it from the page cache
+// and then does count reads of readSize bytes at uniformly random offsets.
+// Without the eviction the reads would mostly measure memory.
+func benchmarkRandomRead(dir string, fileSize int64, readSize int, count int) (*RandomReadResult, error) {
+	f, err := createTemp(dir, "random_read_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	chunk := make([]byte, 1024*1024)
+	if err := fillContent(chunk); err != nil {
+		return nil, err
+	}
+
+	for written := int64(0); written < fileSize; {
+		n, err := f.Write(chunk[:min(int64(len(chunk)), fileSize-written)])
+		if err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+		written += int64(n)
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := evictPageCache(f); err != nil {
+		return nil, fmt.Errorf("evict temp file from page cache: %w", err)
+	}
+
+	buf := make([]byte, readSize)
+	samples := make([]time.Duration, 0, count)
+
+	start := time.Now()
+
+	for range count {
+		off := rand.Int64N(fileSize - int64(readSize) + 1)
+
+		read := time.Now()
+		if _, err := f.ReadAt(buf, off); err != nil {
+			return nil, fmt.Errorf("read temp file at %d: %w", off, err)
+		}
+		samples = append(samples, time.Since(read))
+	}
+
+	since := time.Since(start)
+
+	return &RandomReadResult{
+		FileSize: fileSize,
+		ReadSize: readSize,
+		Count:    count,
+		Seconds:  float32(since) / float32(time.Second),
+		IOPS:     float64(count) / since.Seconds(),
+		ReadGBps: float64(count) * float64(readSize) / (1 << 30) / since.Seconds(),
+		Latency:  latencyStats(samples),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+func benchReadAfterWrite(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		ReadAfterWriteResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkReadAfterWrite(dir, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.ReadAfterWriteResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type ReadAfterWriteResult struct {
+	Count                 int
+	InconsistentReads     int
+	InconsistencyDetected bool
+	MaxInconsistencyMs    float64
+}
+
+// readAfterWriteDeadline bounds how long a single file is re-read before the
+// benchmark gives up waiting for it to become consistent.
+const readAfterWriteDeadline = 10 * time.Second
+
+// benchmarkReadAfterWrite writes count files with unique content and reads
+// each back as soon as it is closed. A read whose SHA-256 differs from what
+// was written is retried until it matches, and the time from the end of the
+// write to the first consistent read is recorded.
+func benchmarkReadAfterWrite(dir string, count int) (*ReadAfterWriteResult, error) {
+	res := &ReadAfterWriteResult{Count: count}
+
+	content := make([]byte, 4096)
+
+	for range count {
+		if _, err := crand.Read(content); err != nil {
+			return nil, fmt.Errorf("random bytes: %w", err)
+		}
+		want := sha256.Sum256(content)
+
+		f, err := createTemp(dir, "read_after_write_*")
+		if err != nil {
+			return nil, fmt.Errorf("create temp file: %w", err)
+		}
+		name := f.Name()
+
+		_, err = f.Write(content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			removeTemp(name)
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+
+		written := time.Now()
+		consistent := true
+		var lag time.Duration
+
+		for {
+			got, err := os.ReadFile(name)
+			if err != nil {
+				removeTemp(name)
+				return nil, fmt.Errorf("read temp file: %w", err)
+			}
+
+			if sum := sha256.Sum256(got); bytes.Equal(sum[:], want[:]) {
+				lag = time.Since(written)
+				break
+			}
+
+			consistent = false
+			if time.Since(written) > readAfterWriteDeadline {
+				removeTemp(name)
+				return nil, fmt.Errorf("read temp file: content still stale after %s", readAfterWriteDeadline)
+			}
+		}
+
+		if !consistent {
+			res.InconsistentReads++
+			res.InconsistencyDetected = true
+			res.MaxInconsistencyMs = max(res.MaxInconsistencyMs, float64(lag)/float64(time.Millisecond))
+		}
+
+		if err := removeTemp(name); err != nil {
+			return nil, fmt.Errorf("remove temp file: %w", err)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+func benchReadOnlyFS(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		ReadOnlyFSResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	files, err := queryInt(r, "files", 100, 1, 10000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkReadOnlyFS(dir, files)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.ReadOnlyFSResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// ReadOnlyFSResult compares reading the same files from a read-write and a
+// read-only mount. ReadOnlyOverhead is how much slower the read-only reads
+// were, as a fraction of the read-write throughput; it is negative when they
+// were faster. Without permission to mount, only ReadWriteGBps is set and
+// Warning says why.
+type ReadOnlyFSResult struct {
+	Files            int
+	FileSize         int
+	ReadOnlySupport  bool
+	ReadWriteGBps    float64
+	ReadOnlyGBps     float64
+	ReadOnlyOverhead float64
+	Warning          string `json:",omitempty"`
+}
+
+const readOnlyFileSize = 1024 * 1024
+
+// benchmarkReadOnlyFS writes fileCount files of readOnlyFileSize bytes to a
+// temp dir in dir and reads them all, evicted from the page cache, once as
+// they are and once with the temp dir mounted read-only.
+func benchmarkReadOnlyFS(dir string, fileCount int) (*ReadOnlyFSResult, error) {
+	tmp, err := mkdirTemp(dir, "readonly_fs_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(tmp)
+
+	buf := make([]byte, readOnlyFileSize)
+	if err := fillContent(buf); err != nil {
+		return nil, err
+	}
+
+	// The files are synced so none of their pages are dirty, which the
+	// page cache would not evict.
+	names := make([]string, fileCount)
+	for i := range names {
+		names[i] = filepath.Join(tmp, strconv.Itoa(i))
+		if err := writeSyncedFile(names[i], buf); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &ReadOnlyFSResult{Files: fileCount, FileSize: readOnlyFileSize}
+
+	if res.ReadWriteGBps, err = readFilesGBps(names, buf); err != nil {
+		return nil, &StepError{"read-write", err}
+	}
+
+	unmount, err := mountReadOnly(tmp)
+	if err != nil {
+		res.Warning = fmt.Sprintf("read-only mount is not available, so only the read-write throughput was measured: %v", err)
+		return res, nil
+	}
+
+	res.ReadOnlySupport = true
+
+	// The mount must really be read-only, or the comparison means nothing.
+	if f, err := os.Create(filepath.Join(tmp, "probe")); err == nil {
+		f.Close()
+		unmount()
+		return nil, &StepError{"read-only", fmt.Errorf("read-only mount of %s accepted a write", tmp)}
+	} else if !errors.Is(err, syscall.EROFS) {
+		unmount()
+		return nil, &StepError{"read-only", fmt.Errorf("probe read-only mount: %w", err)}
+	}
+
+	res.ReadOnlyGBps, err = readFilesGBps(names, buf)
+	if uerr := unmount(); err == nil {
+		err = uerr
+	}
+	if err != nil {
+		return nil, &StepError{"read-only", err}
+	}
+
+	res.ReadOnlyOverhead = (res.ReadWriteGBps - res.ReadOnlyGBps) / res.ReadWriteGBps
+
+	return res, nil
+}
+
+// readFilesGBps evicts every file in names from the page cache and then
+// reads them all through buf.
+func readFilesGBps(names []string, buf []byte) (float64, error) {
+	for _, name := range names {
+		if err := evictFile(name); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int64
+	start := time.Now()
+
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return 0, fmt.Errorf("open file: %w", err)
+		}
+		n, err := io.CopyBuffer(io.Discard, struct{ io.Reader }{f}, buf)
+		f.Close()
+		if err != nil {
+			return 0, fmt.Errorf("read file: %w", err)
+		}
+		total += n
+	}
+
+	return float64(total) / (1 << 30) / time.Since(start).Seconds(), nil
+}
+
+func writeSyncedFile(name string, data []byte) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync file: %w", err)
+	}
+	return f.Close()
+}
+
+func evictFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	if err := evictPageCache(f); err != nil {
+		return fmt.Errorf("evict file from page cache: %w", err)
+	}
+	return nil
+}
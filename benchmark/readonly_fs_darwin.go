@@ -0,0 +1,8 @@
+package main
+
+import "errors"
+
+// mountReadOnly is only implemented on Linux, which has bind mounts.
+func mountReadOnly(dir string) (func() error, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// mountReadOnly bind mounts dir onto itself and remounts the bind read-only,
+// so only dir changes and not the filesystem it is on. It needs
+// CAP_SYS_ADMIN. The returned func undoes the mount.
+func mountReadOnly(dir string) (func() error, error) {
+	if err := syscall.Mount(dir, dir, "", syscall.MS_BIND, ""); err != nil {
+		return nil, fmt.Errorf("bind mount %s: %w", dir, err)
+	}
+	if err := syscall.Mount("", dir, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY, ""); err != nil {
+		syscall.Unmount(dir, syscall.MNT_DETACH)
+		return nil, fmt.Errorf("remount %s read-only: %w", dir, err)
+	}
+
+	return func() error {
+		if err := syscall.Unmount(dir, 0); err != nil {
+			// Busy; detach it so the temp dir can still be removed.
+			if err := syscall.Unmount(dir, syscall.MNT_DETACH); err != nil {
+				return fmt.Errorf("unmount %s: %w", dir, err)
+			}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"benchmark/pkg/report"
+)
+
+// benchReportPDF renders the stored disk benchmark results between the from
+// and to query parameters, both RFC3339 and optional, as a PDF. Every stored
+// run becomes its own entry, labelled with its disk and time, so runs can be
+// compared over the period.
+func benchReportPDF(w http.ResponseWriter, r *http.Request) {
+	requestID := requestIDFromContext(r.Context())
+
+	var from, to time.Time
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if v := r.URL.Query().Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "query parameter "+p.name+": "+err.Error(), 400)
+				return
+			}
+			*p.dst = t
+		}
+	}
+
+	results, err := resultStore.Read()
+	if err != nil {
+		fmt.Println(requestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	hostname, _ := os.Hostname()
+
+	var aggregated []report.AggregatedResult
+
+	for _, res := range results {
+		if !from.IsZero() && res.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && res.Timestamp.After(to) {
+			continue
+		}
+
+		b, err := json.Marshal(res.Result)
+		if err != nil {
+			fmt.Println(requestID, err)
+			writeJSONError(w, 500, err, "encode result")
+			return
+		}
+
+		aggregated = append(aggregated, report.AggregatedResult{
+			Agent:     res.Disk + " " + res.Timestamp.UTC().Format(time.DateTime),
+			CloudMeta: report.CloudMeta(cloudMeta),
+			Results:   map[string]json.RawMessage{"/" + res.Disk + "-disk": b},
+		})
+	}
+
+	gen := report.ReportGenerator{Title: "Benchmark report for " + hostname}
+
+	var buf bytes.Buffer
+	if err := gen.Generate(&buf, aggregated); err != nil {
+		fmt.Println(requestID, err)
+		writeJSONError(w, 500, err, "generate report")
+		return
+	}
+
+	w.Header().Set("content-type", "application/pdf")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+)
+
+// BenchmarkRequest is the JSON body accepted by the POST variants of the disk
+// endpoints. Size classes left out of Classes run with their default
+// parameters, and so do fields left out of a ClassRequest.
+type BenchmarkRequest struct {
+	Classes map[string]ClassRequest `json:"classes"`
+}
+
+type ClassRequest struct {
+	Count     *int              `json:"count"`
+	SizeRange *SizeRangeRequest `json:"size_range"`
+}
+
+type SizeRangeRequest struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validate returns every problem found in the request, in a stable order.
+// Overridden size ranges must be wide enough to spread srcCount source files
+// over, which the benchmark would otherwise only find out after writing
+// them.
+func (req BenchmarkRequest) Validate(srcCount int) []ValidationError {
+	var errs []ValidationError
+
+	known := map[string]bool{}
+	for _, class := range defaultSizeClasses() {
+		known[class.Name] = true
+	}
+
+	names := make([]string, 0, len(req.Classes))
+	for name := range req.Classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := "classes." + name
+		if !known[name] {
+			errs = append(errs, ValidationError{field, "unknown size class"})
+			continue
+		}
+
+		class := req.Classes[name]
+		if class.Count != nil && *class.Count < 0 {
+			errs = append(errs, ValidationError{field + ".count", "must not be negative"})
+		}
+		if class.Count != nil && *class.Count > maxClassCount {
+			errs = append(errs, ValidationError{field + ".count", fmt.Sprintf("must be at most %d", maxClassCount)})
+		}
+		if sr := class.SizeRange; sr != nil {
+			if sr.Min < 1 {
+				errs = append(errs, ValidationError{field + ".size_range.min", "must be at least 1"})
+			}
+			if sr.Min >= sr.Max {
+				errs = append(errs, ValidationError{field + ".size_range", fmt.Sprintf("min (%d) must be less than max (%d)", sr.Min, sr.Max)})
+			}
+			if sr.Max > maxSizeRangeBytes {
+				errs = append(errs, ValidationError{field + ".size_range.max", fmt.Sprintf("must be at most %d", maxSizeRangeBytes)})
+			}
+			if sr.Min >= 1 && sr.Min < sr.Max && sr.Max <= maxSizeRangeBytes {
+				if err := checkSizeDistribution(srcFileSizes(SizeRange{sr.Min, sr.Max}, srcCount)); err != nil {
+					errs = append(errs, ValidationError{field + ".size_range", fmt.Sprintf("too narrow for %d src files: %v", srcCount, err)})
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// sizeClasses returns the default size classes with the overrides in req
+// applied. req must have been validated.
+func (req BenchmarkRequest) sizeClasses() []SizeClass {
+	classes := defaultSizeClasses()
+
+	for i, class := range classes {
+		override, ok := req.Classes[class.Name]
+		if !ok {
+			continue
+		}
+		if override.Count != nil {
+			classes[i].Count = *override.Count
+		}
+		if override.SizeRange != nil {
+			classes[i].SizeRange = SizeRange{override.SizeRange.Min, override.SizeRange.Max}
+		}
+	}
+
+	return classes
+}
+
+// diskOptionsFromRequest returns the options a disk endpoint should run with.
+// GET requests run the default size classes. For POST requests the body is
+// decoded and validated. The src_count and classes query parameters apply to
+// both; size classes left out of classes are not run. On failure an error
+// response is written and ok is false.
+func diskOptionsFromRequest(w http.ResponseWriter, r *http.Request) (opts DiskOptions, ok bool) {
+	srcCount, err := queryInt(r, "src_count", srcFileCount, 1, maxSrcFileCount)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return DiskOptions{}, false
+	}
+
+	selected, err := queryClasses(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return DiskOptions{}, false
+	}
+
+	if r.Method != http.MethodPost {
+		return DiskOptions{Classes: selectClasses(defaultSizeClasses(), selected), SrcFileCount: srcCount}, true
+	}
+
+	var req BenchmarkRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "decode request body: "+err.Error(), 400)
+		return DiskOptions{}, false
+	}
+
+	if errs := req.Validate(srcCount); len(errs) > 0 {
+		type Response struct {
+			Errors []ValidationError `json:"errors"`
+		}
+
+		writePrettyJSON(w, 422, Response{errs}, queryPretty(r))
+		return DiskOptions{}, false
+	}
+
+	return DiskOptions{Classes: selectClasses(req.sizeClasses(), selected), SrcFileCount: srcCount}, true
+}
+
+// selectClasses returns the classes whose names are in selected, or all of
+// them when selected is nil.
+func selectClasses(classes []SizeClass, selected map[string]bool) []SizeClass {
+	if selected == nil {
+		return classes
+	}
+	return slices.DeleteFunc(classes, func(class SizeClass) bool {
+		return !selected[class.Name]
+	})
+}
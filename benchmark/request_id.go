@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	crand "crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware makes sure every request carries an ID. It uses the
+// incoming X-Request-ID header when present and generates a UUID v4
+// otherwise. The ID is echoed on the response and stored in the request
+// context, where handlers read it with requestIDFromContext.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			var err error
+			if id, err = newUUIDv4(); err != nil {
+				fmt.Println(err)
+				writeJSONError(w, 500, err, "")
+				return
+			}
+		}
+
+		w.Header().Set("X-Request-ID", id)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("random bytes: %w", err)
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// writePrettyJSON writes v as the JSON response with the given status,
+// indented with two spaces when pretty is set. Handlers pass queryPretty(r)
+// so every endpoint honours ?pretty=true.
+func writePrettyJSON(w http.ResponseWriter, status int, v any, pretty bool) {
+	b, err := marshalJSON(v, pretty)
+	if err != nil {
+		writeJSONError(w, 500, err, "encode response")
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+// writeCachedJSON is writePrettyJSON for read-only endpoints that clients
+// poll. The response carries an ETag of the SHA-256 of its body, and a request
+// whose If-None-Match lists that tag gets an empty 304 instead.
+func writeCachedJSON(w http.ResponseWriter, r *http.Request, v any) {
+	b, err := marshalJSON(v, queryPretty(r))
+	if err != nil {
+		writeJSONError(w, 500, err, "encode response")
+		return
+	}
+
+	sum := sha256.Sum256(b)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	w.Header().Set("etag", etag)
+
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if tag = strings.TrimSpace(tag); tag == etag || tag == "W/"+etag || tag == "*" {
+			w.WriteHeader(304)
+			return
+		}
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(200)
+	w.Write(b)
+}
+
+func marshalJSON(v any, pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// resultSchemaVersion is the version written with every new StoredResult.
+// Bump it whenever the persisted shape of a result changes.
+const resultSchemaVersion = 5
+
+// StoredResult is a disk benchmark run as persisted by ResultStore.
+// SchemaVersion is the version the record was written with; records from
+// before versioning was introduced read as version 0.
+type StoredResult struct {
+	SchemaVersion int
+	ID            string
+	Timestamp     time.Time
+	Disk          string
+	Result        DiskBenchmarkResult
+}
+
+// ResultStore keeps disk benchmark results as newline-delimited JSON in a
+// single append-only file.
+type ResultStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewResultStore(path string) *ResultStore {
+	return &ResultStore{path: path}
+}
+
+// Append persists res, stamping it with the current schema version.
+func (s *ResultStore) Append(res StoredResult) error {
+	res.SchemaVersion = resultSchemaVersion
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Errorf("marshal result: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open result store: %w", err)
+	}
+
+	_, err = f.Write(append(b, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("write result store: %w", err)
+	}
+
+	return nil
+}
+
+// Read returns every stored result, oldest first. A store that has never
+// been written to is empty. Records written with an older schema are decoded
+// into the current StoredResult, so fields they predate are left at their
+// zero value, and keep their original SchemaVersion.
+func (s *ResultStore) Read() ([]StoredResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("open result store: %w", err)
+	}
+	defer f.Close()
+
+	var results []StoredResult
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var res StoredResult
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+			return nil, fmt.Errorf("decode result store: %w", err)
+		}
+		results = append(results, res)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read result store: %w", err)
+	}
+
+	return results, nil
+}
+
+// latestResult returns the most recent of results for disk, or nil if there
+// is none. results must be in the order Read returns them.
+func latestResult(results []StoredResult, disk string) *StoredResult {
+	var latest *StoredResult
+	for i := range results {
+		if results[i].Disk == disk {
+			latest = &results[i]
+		}
+	}
+	return latest
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// benchResultsDiff serves GET /results/diff?from=<id>&to=<id>, listing every
+// field that differs between two stored results.
+func benchResultsDiff(w http.ResponseWriter, r *http.Request) {
+	fromID, toID := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if fromID == "" || toID == "" {
+		http.Error(w, "query parameters from and to are required", 400)
+		return
+	}
+
+	results, err := resultStore.Read()
+	if err != nil {
+		fmt.Println(requestIDFromContext(r.Context()), err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	var from, to *StoredResult
+	for i := range results {
+		switch results[i].ID {
+		case fromID:
+			from = &results[i]
+		case toID:
+			to = &results[i]
+		}
+	}
+	if from == nil {
+		http.Error(w, "no result with id "+fromID, 404)
+		return
+	}
+	if to == nil {
+		http.Error(w, "no result with id "+toID, 404)
+		return
+	}
+
+	writeCachedJSON(w, r, diffResults(from, to))
+}
+
+type DiffResult struct {
+	From    string
+	To      string
+	Changes []FieldChange
+}
+
+// FieldChange is a field whose value differs. Field is the dotted path used by
+// /benchmark-history, such as Result.TinyRW.Seconds.
+type FieldChange struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// diffResults compares what was measured in from and to. ID and Timestamp
+// always differ and are left out.
+func diffResults(from, to *StoredResult) DiffResult {
+	res := DiffResult{From: from.ID, To: to.ID, Changes: []FieldChange{}}
+
+	diffValues("SchemaVersion", reflect.ValueOf(from.SchemaVersion), reflect.ValueOf(to.SchemaVersion), &res.Changes)
+	diffValues("Disk", reflect.ValueOf(from.Disk), reflect.ValueOf(to.Disk), &res.Changes)
+	diffValues("Result", reflect.ValueOf(from.Result), reflect.ValueOf(to.Result), &res.Changes)
+
+	return res
+}
+
+// diffValues appends a FieldChange for every leaf under path where a and b
+// differ. Structs are walked field by field, with embedded fields flattened
+// into their parent; a pointer that is nil on only one side is reported as a
+// whole, and so is anything that is neither a pointer nor a struct.
+func diffValues(path string, a, b reflect.Value, changes *[]FieldChange) {
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			*changes = append(*changes, FieldChange{path, a.Interface(), b.Interface()})
+			return
+		}
+		diffValues(path, a.Elem(), b.Elem(), changes)
+
+	case reflect.Struct:
+		for i := range a.NumField() {
+			field := a.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := path + "." + field.Name
+			if field.Anonymous {
+				fieldPath = path
+			}
+			diffValues(fieldPath, a.Field(i), b.Field(i), changes)
+		}
+
+	default:
+		*changes = append(*changes, FieldChange{path, a.Interface(), b.Interface()})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"time"
+)
+
+func benchReverseProxy(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		ReverseProxyResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	bodyKB, err := queryInt(r, "body_kb", 64, 1, 16*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	requests, err := queryInt(r, "requests", 1000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkReverseProxy(bodyKB*1024, requests)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.ReverseProxyResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// ReverseProxyResult compares fetching from the backend directly with going
+// through the proxy. AddedLatencyMs is the difference of the medians.
+type ReverseProxyResult struct {
+	BodySize       int
+	Requests       int
+	Direct         ProxyRun
+	Proxied        ProxyRun
+	AddedLatencyMs float64
+}
+
+type ProxyRun struct {
+	ThroughputMBps float64
+	Latency        LatencyStats
+}
+
+// benchmarkReverseProxy starts a backend serving a body of bodySize bytes and
+// an httputil.ReverseProxy in front of it, both on httptest servers, and
+// sends requests GET requests one after the other to each.
+func benchmarkReverseProxy(bodySize int, requests int) (*ReverseProxyResult, error) {
+	body := make([]byte, bodySize)
+	if err := fillContent(body); err != nil {
+		return nil, err
+	}
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/octet-stream")
+		w.Write(body)
+	}))
+	defer backend.Close()
+
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse backend url: %w", err)
+	}
+
+	proxy := httptest.NewServer(httputil.NewSingleHostReverseProxy(backendURL))
+	defer proxy.Close()
+
+	res := &ReverseProxyResult{BodySize: bodySize, Requests: requests}
+
+	if res.Direct, err = runProxyRequests(backend.Client(), backend.URL, requests); err != nil {
+		return nil, &StepError{"direct", err}
+	}
+	if res.Proxied, err = runProxyRequests(proxy.Client(), proxy.URL, requests); err != nil {
+		return nil, &StepError{"proxied", err}
+	}
+
+	res.AddedLatencyMs = res.Proxied.Latency.P50Ms - res.Direct.Latency.P50Ms
+
+	return res, nil
+}
+
+func runProxyRequests(client *http.Client, url string, requests int) (ProxyRun, error) {
+	defer client.CloseIdleConnections()
+
+	samples := make([]time.Duration, 0, requests)
+	var bytes int64
+
+	start := time.Now()
+
+	for range requests {
+		sent := time.Now()
+
+		resp, err := client.Get(url)
+		if err != nil {
+			return ProxyRun{}, fmt.Errorf("get: %w", err)
+		}
+		n, err := io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return ProxyRun{}, fmt.Errorf("read response: %w", err)
+		}
+		if resp.StatusCode != 200 {
+			return ProxyRun{}, fmt.Errorf("get: status %s", resp.Status)
+		}
+
+		samples = append(samples, time.Since(sent))
+		bytes += n
+	}
+
+	return ProxyRun{
+		ThroughputMBps: float64(bytes) / (1 << 20) / time.Since(start).Seconds(),
+		Latency:        latencyStats(samples),
+	}, nil
+}
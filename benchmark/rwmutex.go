@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+func benchRWMutex(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		RWMutexResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	readers, err := queryInt(r, "readers", 8, 0, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	writers, err := queryInt(r, "writers", 1, 0, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if readers+writers == 0 {
+		http.Error(w, "query parameters readers and writers: at least one must be set", 400)
+		return
+	}
+
+	iterations, err := queryInt(r, "iterations", 100000, 1, 10000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.RWMutexResult = *benchmarkRWMutex(readers, writers, iterations)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type RWMutexResult struct {
+	Readers    int
+	Writers    int
+	Iterations int
+	RWMutex    LockRun
+	Mutex      LockRun
+}
+
+// LockRun is one run of the readers and writers against a lock. The latencies
+// are the time from asking for the lock to getting it.
+type LockRun struct {
+	AcquisitionsPerSec float64
+	ReadLatency        LatencyStats
+	WriteLatency       LatencyStats
+}
+
+// benchmarkRWMutex has readers goroutines take a read lock and writers
+// goroutines take the write lock, iterations times each, on one
+// sync.RWMutex. The readers read and the writers increment a shared counter.
+// The same load is then run with a sync.Mutex, which the readers take
+// exclusively.
+func benchmarkRWMutex(readers, writers, iterations int) *RWMutexResult {
+	var rw sync.RWMutex
+	var mu sync.Mutex
+
+	return &RWMutexResult{
+		Readers:    readers,
+		Writers:    writers,
+		Iterations: iterations,
+		RWMutex:    runLockLoad(rw.RLock, rw.RUnlock, rw.Lock, rw.Unlock, readers, writers, iterations),
+		Mutex:      runLockLoad(mu.Lock, mu.Unlock, mu.Lock, mu.Unlock, readers, writers, iterations),
+	}
+}
+
+func runLockLoad(rlock, runlock, lock, unlock func(), readers, writers, iterations int) LockRun {
+	// counter is the value the goroutines share under the lock.
+	var counter int
+
+	readSamples := make([][]time.Duration, readers)
+	writeSamples := make([][]time.Duration, writers)
+
+	since := runConcurrently(readers+writers, func(i int) {
+		samples := make([]time.Duration, 0, iterations)
+
+		if i < readers {
+			for range iterations {
+				lockStart := time.Now()
+				rlock()
+				samples = append(samples, time.Since(lockStart))
+				_ = counter
+				runlock()
+			}
+			readSamples[i] = samples
+			return
+		}
+
+		for range iterations {
+			lockStart := time.Now()
+			lock()
+			samples = append(samples, time.Since(lockStart))
+			counter++
+			unlock()
+		}
+		writeSamples[i-readers] = samples
+	})
+
+	return LockRun{
+		AcquisitionsPerSec: float64((readers+writers)*iterations) / since.Seconds(),
+		ReadLatency:        latencyStats(slices.Concat(readSamples...)),
+		WriteLatency:       latencyStats(slices.Concat(writeSamples...)),
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import "math"
+
+// The composite score is the weighted mean of one score per metric. A metric
+// scores 100 at its baseline and proportionally less below it; values above
+// the baseline are capped so one very fast disk cannot hide a slow one.
+
+// scoreMetric is a metric that goes into the composite score.
+type scoreMetric struct {
+	disk     string
+	baseline float64
+	weight   float64
+	value    func(*DiskResult) float64
+}
+
+// scoreMetrics is the baseline table. The baselines are roughly what a local
+// NVMe disk reaches with the default size classes.
+var scoreMetrics = map[string]scoreMetric{
+	"ephemeral_iops":        {"ephemeral", ephemeralIOPSBaseline, 0.2, (*DiskResult).IOPS},
+	"ephemeral_throughput":  {"ephemeral", ephemeralThroughputBaseline, 0.2, (*DiskResult).ThroughputMBps},
+	"persistent_iops":       {"persistent", persistentIOPSBaseline, 0.3, (*DiskResult).IOPS},
+	"persistent_throughput": {"persistent", persistentThroughputBaseline, 0.3, (*DiskResult).ThroughputMBps},
+}
+
+const (
+	ephemeralIOPSBaseline        = 20000 // files per second
+	ephemeralThroughputBaseline  = 1000  // MiB per second
+	persistentIOPSBaseline       = 5000  // files per second
+	persistentThroughputBaseline = 250   // MiB per second
+)
+
+// Grade thresholds on the composite score. Anything below gradeD is an F.
+const (
+	gradeA = 90
+	gradeB = 75
+	gradeC = 60
+	gradeD = 40
+)
+
+// metricScore returns the 0 to 100 score of value against baseline.
+func metricScore(value, baseline float64) float64 {
+	return 100 * math.Min(value/baseline, 1)
+}
+
+func grade(score float64) string {
+	switch {
+	case score >= gradeA:
+		return "A"
+	case score >= gradeB:
+		return "B"
+	case score >= gradeC:
+		return "C"
+	case score >= gradeD:
+		return "D"
+	default:
+		return "F"
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func benchSerialization(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		SerializationResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	structSize := r.URL.Query().Get("size")
+	if structSize == "" {
+		structSize = "medium"
+	}
+	if _, ok := serialSampleSizes[structSize]; !ok {
+		http.Error(w, fmt.Sprintf("query parameter size: unknown size %q", structSize), 400)
+		return
+	}
+
+	formats := []string{"json", "gob", "protobuf"}
+	if v := r.URL.Query().Get("formats"); v != "" {
+		formats = strings.Split(v, ",")
+		for _, format := range formats {
+			if _, ok := serialFormats[format]; !ok {
+				http.Error(w, fmt.Sprintf("query parameter formats: unknown format %q", format), 400)
+				return
+			}
+		}
+	}
+
+	res, err := benchmarkSerialization(structSize, formats)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.SerializationResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type SerializationResult struct {
+	StructSize string
+	Formats    map[string]SerializationRun
+}
+
+type SerializationRun struct {
+	OpsPerSec    float64
+	MBps         float64
+	EncodedBytes int
+}
+
+// serialSample is the struct every format encodes. Its size is set by how
+// many tags, scores and children it gets.
+type serialSample struct {
+	ID       int64
+	Name     string
+	Created  time.Time
+	Tags     []string
+	Scores   map[string]float64
+	Children []serialSample
+}
+
+var serialSampleSizes = map[string]func() serialSample{
+	"small":  func() serialSample { return newSerialSample(0, 2, 2, 0) },
+	"medium": func() serialSample { return newSerialSample(0, 10, 10, 10) },
+	"large":  func() serialSample { return newSerialSample(0, 50, 50, 100) },
+}
+
+func newSerialSample(id int64, tags, scores, children int) serialSample {
+	s := serialSample{
+		ID:      id,
+		Name:    "sample-" + strconv.FormatInt(id, 10),
+		Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(id) * time.Minute),
+		Scores:  map[string]float64{},
+	}
+	for i := range tags {
+		s.Tags = append(s.Tags, "tag-"+strconv.Itoa(i))
+	}
+	for i := range scores {
+		s.Scores["score-"+strconv.Itoa(i)] = float64(i) * 1.5
+	}
+	for i := range children {
+		s.Children = append(s.Children, newSerialSample(id*1000+int64(i)+1, 5, 5, 0))
+	}
+	return s
+}
+
+// serialFormat returns a round trip function for sample: one call encodes and
+// decodes it once and returns the encoded size.
+type serialFormat func(sample serialSample) (func() (int, error), error)
+
+var serialFormats = map[string]serialFormat{
+	"json": func(sample serialSample) (func() (int, error), error) {
+		return func() (int, error) {
+			b, err := json.Marshal(sample)
+			if err != nil {
+				return 0, err
+			}
+			var out serialSample
+			return len(b), json.Unmarshal(b, &out)
+		}, nil
+	},
+
+	// Every round trip uses a new encoder and decoder, so the type
+	// description is sent each time, as it is for one-off messages.
+	"gob": func(sample serialSample) (func() (int, error), error) {
+		return func() (int, error) {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(sample); err != nil {
+				return 0, err
+			}
+			n := buf.Len()
+			var out serialSample
+			return n, gob.NewDecoder(&buf).Decode(&out)
+		}, nil
+	},
+
+	// There is no generated code for serialSample, so it is encoded as a
+	// structpb.Struct built from its JSON form. The conversion happens once,
+	// outside the timed loop.
+	"protobuf": func(sample serialSample) (func() (int, error), error) {
+		b, err := json.Marshal(sample)
+		if err != nil {
+			return nil, err
+		}
+		msg := &structpb.Struct{}
+		if err := msg.UnmarshalJSON(b); err != nil {
+			return nil, err
+		}
+
+		return func() (int, error) {
+			b, err := proto.Marshal(msg)
+			if err != nil {
+				return 0, err
+			}
+			return len(b), proto.Unmarshal(b, &structpb.Struct{})
+		}, nil
+	},
+}
+
+// serializationDuration is how long each format runs.
+const serializationDuration = 5 * time.Second
+
+// benchmarkSerialization round trips the sample of structSize through each of
+// formats for serializationDuration.
+func benchmarkSerialization(structSize string, formats []string) (*SerializationResult, error) {
+	sample := serialSampleSizes[structSize]()
+
+	res := &SerializationResult{StructSize: structSize, Formats: map[string]SerializationRun{}}
+
+	for _, format := range formats {
+		roundTrip, err := serialFormats[format](sample)
+		if err != nil {
+			return nil, &StepError{format, err}
+		}
+
+		var ops, size int
+		start := time.Now()
+
+		for time.Since(start) < serializationDuration {
+			if size, err = roundTrip(); err != nil {
+				return nil, &StepError{format, err}
+			}
+			ops++
+		}
+
+		seconds := time.Since(start).Seconds()
+
+		res.Formats[format] = SerializationRun{
+			OpsPerSec:    float64(ops) / seconds,
+			MBps:         float64(ops) * float64(size) / (1 << 20) / seconds,
+			EncodedBytes: size,
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func benchSerializeDisk(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		SerializeDiskResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkSerializeDisk(dir, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.SerializeDiskResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// SerializeDiskResult compares a JSON round trip in memory with one through a
+// file. DiskOverhead is how many times slower the disk round trip was.
+type SerializeDiskResult struct {
+	Count                int
+	EncodedBytes         int
+	InMemoryNsPerOp      float64
+	DiskRoundTripNsPerOp float64
+	DiskOverhead         float64
+}
+
+// benchmarkSerializeDisk marshals the serialization benchmark's large sample
+// to JSON and unmarshals it count times in memory, then count times with a
+// file in dir in between: the JSON is written to it, closed, read back
+// and then unmarshalled. The reads come from the page cache, as they would
+// for a file that was just written.
+func benchmarkSerializeDisk(dir string, count int) (*SerializeDiskResult, error) {
+	sample := serialSampleSizes["large"]()
+
+	tmp, err := mkdirTemp(dir, "serialize_disk_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(tmp)
+
+	path := filepath.Join(tmp, "sample.json")
+	res := &SerializeDiskResult{Count: count}
+
+	start := time.Now()
+	for range count {
+		b, err := json.Marshal(sample)
+		if err != nil {
+			return nil, &StepError{"memory", fmt.Errorf("encode sample: %w", err)}
+		}
+		res.EncodedBytes = len(b)
+
+		var out serialSample
+		if err := json.Unmarshal(b, &out); err != nil {
+			return nil, &StepError{"memory", fmt.Errorf("decode sample: %w", err)}
+		}
+	}
+	res.InMemoryNsPerOp = float64(time.Since(start).Nanoseconds()) / float64(count)
+
+	start = time.Now()
+	for range count {
+		b, err := json.Marshal(sample)
+		if err != nil {
+			return nil, &StepError{"disk", fmt.Errorf("encode sample: %w", err)}
+		}
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			return nil, &StepError{"disk", fmt.Errorf("write sample: %w", err)}
+		}
+
+		b, err = os.ReadFile(path)
+		if err != nil {
+			return nil, &StepError{"disk", fmt.Errorf("read sample: %w", err)}
+		}
+		var out serialSample
+		if err := json.Unmarshal(b, &out); err != nil {
+			return nil, &StepError{"disk", fmt.Errorf("decode sample: %w", err)}
+		}
+	}
+	res.DiskRoundTripNsPerOp = float64(time.Since(start).Nanoseconds()) / float64(count)
+
+	res.DiskOverhead = res.DiskRoundTripNsPerOp / res.InMemoryNsPerOp
+
+	return res, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func benchSQLiteConn(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		SQLiteConnResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000, 1, 1000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkSQLiteConnOpen(dir, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.SQLiteConnResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type SQLiteConnResult struct {
+	Count            int
+	OpenCloseNsPerOp float64
+	PingNsPerOp      float64
+	OpenLatency      LatencyStats
+}
+
+// benchmarkSQLiteConnOpen creates a SQLite database in a temp dir in dir and
+// opens and closes a connection to it count times. sql.Open does not connect,
+// so every cycle pings the database to establish the connection. The
+// baseline pings a single connection that stays open count times.
+func benchmarkSQLiteConnOpen(dir string, count int) (*SQLiteConnResult, error) {
+	tmp, err := mkdirTemp(dir, "sqlite_conn_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(tmp)
+
+	dsn := filepath.Join(tmp, "bench.db")
+
+	// Create the database file up front, so the open cycles do not include
+	// creating it.
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS t (id INTEGER PRIMARY KEY)"); err != nil {
+		return nil, fmt.Errorf("create table: %w", err)
+	}
+
+	samples := make([]time.Duration, 0, count)
+
+	start := time.Now()
+
+	for range count {
+		openStart := time.Now()
+
+		conn, err := sql.Open("sqlite", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("open database: %w", err)
+		}
+		err = conn.Ping()
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("connect to database: %w", err)
+		}
+
+		samples = append(samples, time.Since(openStart))
+	}
+
+	openClose := time.Since(start)
+
+	start = time.Now()
+
+	for range count {
+		if err := db.Ping(); err != nil {
+			return nil, fmt.Errorf("ping database: %w", err)
+		}
+	}
+
+	ping := time.Since(start)
+
+	return &SQLiteConnResult{
+		Count:            count,
+		OpenCloseNsPerOp: float64(openClose.Nanoseconds()) / float64(count),
+		PingNsPerOp:      float64(ping.Nanoseconds()) / float64(count),
+		OpenLatency:      latencyStats(samples),
+	}, nil
+}
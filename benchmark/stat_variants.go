@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func benchStatVariants(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		StatVariantsResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100000, 1, 100000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkStatVariants(dir, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.StatVariantsResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type StatVariantsResult struct {
+	Count   int
+	Regular StatRun
+	Symlink StatRun
+}
+
+// StatRun is the cost of each stat function on one path. FileStat is
+// os.File.Stat on the path opened once, which for a symlink is its target.
+type StatRun struct {
+	FileStatNsPerOp float64
+	StatNsPerOp     float64
+	LstatNsPerOp    float64
+}
+
+// benchmarkStatVariants creates a regular file and a symlink to it in a temp
+// dir in dir and calls os.File.Stat, os.Stat and os.Lstat count times each on
+// both.
+func benchmarkStatVariants(dir string, count int) (*StatVariantsResult, error) {
+	tmp, err := mkdirTemp(dir, "stat_variants_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer removeTemp(tmp)
+
+	regular := filepath.Join(tmp, "file")
+	symlink := filepath.Join(tmp, "link")
+
+	if err := os.WriteFile(regular, []byte("stat variants\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("write temp file: %w", err)
+	}
+	if err := os.Symlink(regular, symlink); err != nil {
+		return nil, fmt.Errorf("create symlink: %w", err)
+	}
+
+	res := &StatVariantsResult{Count: count}
+
+	if res.Regular, err = runStatVariants(regular, count); err != nil {
+		return nil, &StepError{"regular", err}
+	}
+	if res.Symlink, err = runStatVariants(symlink, count); err != nil {
+		return nil, &StepError{"symlink", err}
+	}
+
+	return res, nil
+}
+
+func runStatVariants(path string, count int) (StatRun, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return StatRun{}, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+
+	var run StatRun
+
+	variants := []struct {
+		nsPerOp *float64
+		stat    func() (os.FileInfo, error)
+	}{
+		{&run.FileStatNsPerOp, f.Stat},
+		{&run.StatNsPerOp, func() (os.FileInfo, error) { return os.Stat(path) }},
+		{&run.LstatNsPerOp, func() (os.FileInfo, error) { return os.Lstat(path) }},
+	}
+
+	for _, v := range variants {
+		start := time.Now()
+		for range count {
+			if _, err := v.stat(); err != nil {
+				return StatRun{}, fmt.Errorf("stat: %w", err)
+			}
+		}
+		*v.nsPerOp = float64(time.Since(start).Nanoseconds()) / float64(count)
+	}
+
+	return run, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func benchStrconv(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		StrconvResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	count, err := queryInt(r, "count", 1000000, 1, 1000000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkStrconv(count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, errorStep(err))
+		return
+	}
+
+	response.StrconvResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// StrconvResult holds the calls per second of each strconv function, keyed
+// by function name. TotalOpsPerSec is their sum.
+type StrconvResult struct {
+	Count          int
+	OpsPerSec      map[string]float64
+	TotalOpsPerSec float64
+}
+
+// strconvFuncs are the conversions benchmarkStrconv times, on inputs typical
+// of log lines and CSV fields. Each runs its conversion count times, keeping
+// the result in a typed local, and returns the last one.
+var strconvFuncs = []struct {
+	name string
+	fn   func(count int) (any, error)
+}{
+	{"ParseFloat", func(count int) (any, error) {
+		var v float64
+		for range count {
+			var err error
+			if v, err = strconv.ParseFloat("12345.6789", 64); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	}},
+	{"ParseInt", func(count int) (any, error) {
+		var v int64
+		for range count {
+			var err error
+			if v, err = strconv.ParseInt("-1234567890", 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	}},
+	{"ParseBool", func(count int) (any, error) {
+		var v bool
+		for range count {
+			var err error
+			if v, err = strconv.ParseBool("true"); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	}},
+	{"Itoa", func(count int) (any, error) {
+		var v string
+		for range count {
+			v = strconv.Itoa(1234567890)
+		}
+		return v, nil
+	}},
+	{"FormatFloat", func(count int) (any, error) {
+		var v string
+		for range count {
+			v = strconv.FormatFloat(12345.6789, 'f', -1, 64)
+		}
+		return v, nil
+	}},
+}
+
+// benchmarkStrconv calls each of strconvFuncs count times.
+func benchmarkStrconv(count int) (*StrconvResult, error) {
+	res := &StrconvResult{Count: count, OpsPerSec: map[string]float64{}}
+
+	for _, f := range strconvFuncs {
+		start := time.Now()
+		v, err := f.fn(count)
+		since := time.Since(start)
+		if err != nil {
+			return nil, &StepError{f.name, err}
+		}
+		keep(v)
+
+		opsPerSec := float64(count) / since.Seconds()
+		res.OpsPerSec[f.name] = opsPerSec
+		res.TotalOpsPerSec += opsPerSec
+	}
+
+	return res, nil
+}
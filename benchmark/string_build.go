@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func benchStringBuild(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		StringBuildResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	n, err := queryInt(r, "n", 100, 1, 10000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	strlen, err := queryInt(r, "len", 16, 1, 1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if n*strlen > maxStringBuildBytes {
+		http.Error(w, fmt.Sprintf("query parameters n and len: the built string must be at most %d bytes", maxStringBuildBytes), 400)
+		return
+	}
+
+	response.StringBuildResult = *benchmarkStringBuilding(n, strlen)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// StringBuildResult holds one run per method, keyed by name. An op is
+// building one complete string.
+type StringBuildResult struct {
+	N       int
+	Len     int
+	Methods map[string]StringBuildRun
+}
+
+type StringBuildRun struct {
+	Ops         int
+	NsPerOp     float64
+	AllocsPerOp float64
+}
+
+const (
+	// maxStringBuildBytes caps the built string, since the fmt.Sprintf method
+	// copies it on every append.
+	maxStringBuildBytes = 1024 * 1024
+
+	stringBuildDuration = time.Second
+)
+
+// benchmarkStringBuilding builds a string by appending a piece of strlen
+// characters n times, with strings.Builder, bytes.Buffer and fmt.Sprintf,
+// each for stringBuildDuration. The allocations are counted with
+// runtime.MemStats and include the final string.
+func benchmarkStringBuilding(n int, strlen int) *StringBuildResult {
+	piece := strings.Repeat("x", strlen)
+
+	methods := map[string]func() string{
+		"strings.Builder": func() string {
+			var b strings.Builder
+			for range n {
+				b.WriteString(piece)
+			}
+			return b.String()
+		},
+		"bytes.Buffer": func() string {
+			var b bytes.Buffer
+			for range n {
+				b.WriteString(piece)
+			}
+			return b.String()
+		},
+		"fmt.Sprintf": func() string {
+			s := ""
+			for range n {
+				s = fmt.Sprintf("%s%s", s, piece)
+			}
+			return s
+		},
+	}
+
+	res := &StringBuildResult{N: n, Len: strlen, Methods: map[string]StringBuildRun{}}
+
+	for name, build := range methods {
+		runtime.GC()
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+
+		var s string
+		ops := 0
+		start := time.Now()
+
+		for time.Since(start) < stringBuildDuration {
+			s = build()
+			ops++
+		}
+
+		since := time.Since(start)
+		runtime.ReadMemStats(&after)
+		keep(s)
+
+		res.Methods[name] = StringBuildRun{
+			Ops:         ops,
+			NsPerOp:     float64(since.Nanoseconds()) / float64(ops),
+			AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(ops),
+		}
+	}
+
+	return res
+}
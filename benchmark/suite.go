@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// BenchmarkSuite runs a list of benchmarks one after the other, so several
+// of them can be combined into one run without going through HTTP.
+type BenchmarkSuite struct {
+	names []string
+	fns   []func(ctx context.Context) (any, error)
+}
+
+// Add appends a benchmark to the suite. Names must be unique.
+func (s *BenchmarkSuite) Add(name string, fn func(ctx context.Context) (any, error)) {
+	s.names = append(s.names, name)
+	s.fns = append(s.fns, fn)
+}
+
+// SuiteResult is the outcome of every benchmark in a suite, keyed by name.
+// Order lists the names in the order they ran.
+type SuiteResult struct {
+	Order   []string
+	Results map[string]BenchmarkRunResult
+}
+
+type BenchmarkRunResult struct {
+	Value    any
+	Duration time.Duration
+	Error    error
+}
+
+func (r BenchmarkRunResult) MarshalJSON() ([]byte, error) {
+	type Result struct {
+		Value   any
+		Seconds float32
+		Error   string `json:",omitempty"`
+	}
+
+	res := Result{Value: r.Value, Seconds: float32(r.Duration) / float32(time.Second)}
+	if r.Error != nil {
+		res.Error = r.Error.Error()
+	}
+	return json.Marshal(res)
+}
+
+// Run runs the benchmarks in the order they were added. A failing benchmark
+// is recorded in its result and does not stop the others; Run only returns an
+// error when ctx is done, together with the results so far.
+func (s *BenchmarkSuite) Run(ctx context.Context) (SuiteResult, error) {
+	res := SuiteResult{Results: map[string]BenchmarkRunResult{}}
+
+	for i, name := range s.names {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		start := time.Now()
+		value, err := s.fns[i](ctx)
+
+		res.Order = append(res.Order, name)
+		res.Results[name] = BenchmarkRunResult{value, time.Since(start), err}
+	}
+
+	return res, nil
+}
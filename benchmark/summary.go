@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// metricsSummary serves GET /metrics/summary: a composite score of the latest
+// stored result of each disk. A disk without a stored result gets a quick run
+// with quickSizeClasses, which is not stored. The quick run takes the
+// benchmark lock, so it gets 409 while another benchmark is running.
+func metricsSummary(w http.ResponseWriter, r *http.Request) {
+	type Detail struct {
+		Value    float64 `json:"value"`
+		Baseline float64 `json:"baseline"`
+		Weight   float64 `json:"weight"`
+		Score    float64 `json:"score"`
+		Source   string  `json:"source"`
+	}
+
+	type Response struct {
+		Score   float64           `json:"score"`
+		Grade   string            `json:"grade"`
+		Details map[string]Detail `json:"details"`
+	}
+
+	requestID := requestIDFromContext(r.Context())
+
+	results, err := resultStore.Read()
+	if err != nil {
+		fmt.Println(requestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	dirs := map[string]string{"ephemeral": ephemeralDir, "persistent": persistentDir}
+
+	totals := map[string]*DiskResult{}
+	sources := map[string]string{}
+
+	for disk, dir := range dirs {
+		if latest := latestResult(results, disk); latest != nil {
+			totals[disk] = diskTotals(latest.Result)
+			sources[disk] = "stored"
+			continue
+		}
+
+		// The quick run uses the disk like any other benchmark, so it takes
+		// the benchmark lock.
+		release, ok := lockBenchmark(w, r)
+		if !ok {
+			return
+		}
+		res, err := benchmarkRWDisk(r.Context(), dir, DiskOptions{Classes: quickSizeClasses(), SrcFileCount: srcFileCount})
+		release()
+		if err != nil {
+			fmt.Println(requestID, err)
+			writeJSONError(w, 500, err, errorStep(err))
+			return
+		}
+		totals[disk] = diskTotals(*res)
+		sources[disk] = "quick"
+	}
+
+	response := Response{Details: map[string]Detail{}}
+
+	names := make([]string, 0, len(scoreMetrics))
+	for name := range scoreMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var weights float64
+	for _, name := range names {
+		metric := scoreMetrics[name]
+		value := metric.value(totals[metric.disk])
+		score := metricScore(value, metric.baseline)
+
+		response.Details[name] = Detail{value, metric.baseline, metric.weight, score, sources[metric.disk]}
+		response.Score += score * metric.weight
+		weights += metric.weight
+	}
+
+	response.Score = math.Round(response.Score/weights*10) / 10
+	response.Grade = grade(response.Score)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// quickSizeClasses is a cut down version of the default size classes that
+// finishes in seconds. The large and huge classes are left out entirely,
+// since even their source files take a while to write.
+func quickSizeClasses() []SizeClass {
+	classes := defaultSizeClasses()[:3]
+	classes[0].Count = 1000
+	classes[1].Count = 100
+	classes[2].Count = 10
+	return classes
+}
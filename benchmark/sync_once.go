@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+func benchSyncOnce(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		SyncOnceResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	goroutines, err := queryInt(r, "goroutines", 8, 1, 10000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100000, 1, 10000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.SyncOnceResult = *benchmarkSyncOnce(goroutines, count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// SyncOnceResult counts one call by one goroutine as an op. OverheadNsPerOp
+// is the difference between going through once.Do and calling the function
+// directly.
+type SyncOnceResult struct {
+	Goroutines      int
+	Count           int
+	OnceNsPerOp     float64
+	DirectNsPerOp   float64
+	OverheadNsPerOp float64
+}
+
+// onceSink keeps the results of the direct calls alive.
+var onceSink atomic.Int64
+
+// benchmarkSyncOnce runs count rounds with a fresh sync.Once each. All
+// goroutines call once.Do in every round, so one of them runs the function
+// and the others either wait for it or take the fast path once it is done.
+// The direct run has the goroutines call the function themselves instead.
+func benchmarkSyncOnce(goroutines int, count int) *SyncOnceResult {
+	initValue := func(round int) int64 { return int64(round) * 2 }
+
+	onces := make([]sync.Once, count)
+	values := make([]int64, count)
+
+	onceTime := runConcurrently(goroutines, func(int) {
+		for round := range count {
+			onces[round].Do(func() { values[round] = initValue(round) })
+		}
+	})
+
+	directTime := runConcurrently(goroutines, func(int) {
+		var sum int64
+		for round := range count {
+			sum += initValue(round)
+		}
+		onceSink.Add(sum)
+	})
+
+	ops := float64(goroutines) * float64(count)
+
+	res := &SyncOnceResult{
+		Goroutines:    goroutines,
+		Count:         count,
+		OnceNsPerOp:   float64(onceTime.Nanoseconds()) / ops,
+		DirectNsPerOp: float64(directTime.Nanoseconds()) / ops,
+	}
+	res.OverheadNsPerOp = res.OnceNsPerOp - res.DirectNsPerOp
+
+	return res
+}
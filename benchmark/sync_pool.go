@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func benchSyncPool(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		SyncPoolResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	objSize, err := queryInt(r, "obj_size", 4096, 1, 64*1024*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	goroutines, err := queryInt(r, "goroutines", 8, 1, 10000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	iterations, err := queryInt(r, "iterations", 1000000, 1, 1000000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.SyncPoolResult = *benchmarkSyncPool(objSize, goroutines, iterations)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type SyncPoolResult struct {
+	ObjectSize      int
+	Goroutines      int
+	Iterations      int
+	PoolOpsPerSec   float64
+	DirectOpsPerSec float64
+	PoolAllocations int64
+	PoolHitRate     float64
+}
+
+// benchmarkSyncPool has goroutines goroutines each do iterations Get/Put
+// cycles on a shared sync.Pool, then repeats the same work allocating a fresh
+// slice every time. The hit rate is estimated from how often the pool had to
+// call New.
+func benchmarkSyncPool(objectSize int, goroutines int, iterations int) *SyncPoolResult {
+	var allocations atomic.Int64
+
+	pool := sync.Pool{
+		New: func() any {
+			allocations.Add(1)
+			b := make([]byte, objectSize)
+			return &b
+		},
+	}
+
+	poolTime := runConcurrently(goroutines, func(int) {
+		for range iterations {
+			b := pool.Get().(*[]byte)
+			(*b)[0]++
+			pool.Put(b)
+		}
+	})
+
+	allocSinks = make([][]byte, goroutines)
+	directTime := runConcurrently(goroutines, func(g int) {
+		for range iterations {
+			b := make([]byte, objectSize)
+			b[0]++
+			allocSinks[g] = b
+		}
+	})
+	allocSinks = nil
+
+	ops := float64(goroutines) * float64(iterations)
+
+	return &SyncPoolResult{
+		ObjectSize:      objectSize,
+		Goroutines:      goroutines,
+		Iterations:      iterations,
+		PoolOpsPerSec:   ops / poolTime.Seconds(),
+		DirectOpsPerSec: ops / directTime.Seconds(),
+		PoolAllocations: allocations.Load(),
+		PoolHitRate:     1 - float64(allocations.Load())/ops,
+	}
+}
+
+// runConcurrently runs fn in n goroutines, passing each its index, and
+// returns the wall time until all of them finished.
+func runConcurrently(n int, fn func(i int)) time.Duration {
+	var wg sync.WaitGroup
+
+	start := time.Now()
+
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+
+	return time.Since(start)
+}
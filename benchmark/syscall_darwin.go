@@ -0,0 +1,22 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// evictPageCache, preallocate and threadRusage are only implemented on
+// Linux, which has fadvise, fallocate and per-thread rusage.
+func evictPageCache(f *os.File) error {
+	return errors.ErrUnsupported
+}
+
+func preallocate(f *os.File, size int64) error {
+	return errors.ErrUnsupported
+}
+
+func threadRusage(ru *unix.Rusage) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// evictPageCache drops the cached pages of f, so the next read of it goes to
+// the device. Dirty pages are not dropped; sync f first.
+func evictPageCache(f *os.File) error {
+	return unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
+
+// preallocate reserves size bytes of disk space for f without changing its
+// size.
+func preallocate(f *os.File, size int64) error {
+	return unix.Fallocate(int(f.Fd()), unix.FALLOC_FL_KEEP_SIZE, 0, size)
+}
+
+// threadRusage returns the resource usage of the calling thread, which must
+// be locked to its goroutine.
+func threadRusage(ru *unix.Rusage) error {
+	return unix.Getrusage(unix.RUSAGE_THREAD, ru)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+func benchTempDirCompare(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		TempDirResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkTempDir(dir)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.TempDirResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type TempDirResult struct {
+	TempDir        TempDirRun
+	CustomDir      TempDirRun
+	SameFilesystem bool
+}
+
+type TempDirRun struct {
+	Path       string
+	Filesystem FilesystemInfo
+	WriteMBps  float64
+	ReadMBps   float64
+}
+
+const (
+	tempDirFiles    = 100
+	tempDirFileSize = 1024 * 1024
+)
+
+// benchmarkTempDir runs the same write and read cycle in os.TempDir() and in
+// customDir. Each file is synced after writing so a tmpfs and a real disk can
+// be told apart.
+func benchmarkTempDir(customDir string) (*TempDirResult, error) {
+	tempDir := os.TempDir()
+
+	var tempSt, customSt syscall.Stat_t
+	if err := syscall.Stat(tempDir, &tempSt); err != nil {
+		return nil, fmt.Errorf("stat %s: %w", tempDir, err)
+	}
+	if err := syscall.Stat(customDir, &customSt); err != nil {
+		return nil, fmt.Errorf("stat %s: %w", customDir, err)
+	}
+
+	tempRun, err := runTempDirCycle(tempDir)
+	if err != nil {
+		return nil, &StepError{"temp dir", err}
+	}
+
+	customRun, err := runTempDirCycle(customDir)
+	if err != nil {
+		return nil, &StepError{"custom dir", err}
+	}
+
+	return &TempDirResult{
+		TempDir:        *tempRun,
+		CustomDir:      *customRun,
+		SameFilesystem: tempSt.Dev == customSt.Dev,
+	}, nil
+}
+
+func runTempDirCycle(dir string) (*TempDirRun, error) {
+	buf := make([]byte, tempDirFileSize)
+	if err := fillContent(buf); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	defer func() {
+		for _, name := range names {
+			removeTemp(name)
+		}
+	}()
+
+	start := time.Now()
+
+	for range tempDirFiles {
+		f, err := createTemp(dir, "temp_dir_*")
+		if err != nil {
+			return nil, fmt.Errorf("create temp file: %w", err)
+		}
+		names = append(names, f.Name())
+
+		_, err = f.Write(buf)
+		if err == nil {
+			err = f.Sync()
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+	}
+
+	writeTime := time.Since(start)
+	start = time.Now()
+
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("open temp file: %w", err)
+		}
+		_, err = io.CopyBuffer(io.Discard, f, buf)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read temp file: %w", err)
+		}
+	}
+
+	readTime := time.Since(start)
+
+	mb := float64(tempDirFiles) * tempDirFileSize / (1 << 20)
+
+	return &TempDirRun{
+		Path:       dir,
+		Filesystem: detectFilesystemType(dir),
+		WriteMBps:  mb / writeTime.Seconds(),
+		ReadMBps:   mb / readTime.Seconds(),
+	}, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+func benchTimeNow(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		TimeNowResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	count, err := queryInt(r, "count", 10000000, 1, 1000000000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	response.TimeNowResult = *benchmarkTimeNow(count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+// TimeNowResult is the cost of reading the clock. UniqueTimestamps is how
+// many distinct wall clock readings timeNowUniqueCalls back to back calls
+// gave, which shows the resolution of the clock source.
+type TimeNowResult struct {
+	Count            int
+	TimeNowNsPerOp   float64
+	TimeSinceNsPerOp float64
+	UniqueTimestamps int
+}
+
+const timeNowUniqueCalls = 1000000
+
+// benchmarkTimeNow calls time.Now and time.Since count times each, and then
+// counts the distinct results of timeNowUniqueCalls calls to time.Now.
+func benchmarkTimeNow(count int) *TimeNowResult {
+	res := &TimeNowResult{Count: count}
+
+	var t time.Time
+	start := time.Now()
+	for range count {
+		t = time.Now()
+	}
+	res.TimeNowNsPerOp = float64(time.Since(start).Nanoseconds()) / float64(count)
+	keep(t)
+
+	var d time.Duration
+	start = time.Now()
+	for range count {
+		d = time.Since(t)
+	}
+	res.TimeSinceNsPerOp = float64(time.Since(start).Nanoseconds()) / float64(count)
+	keep(d)
+
+	// The readings are taken first and compared afterwards, so the counting
+	// does not space out the calls. Consecutive calls never go back in time
+	// unless the clock is stepped, so counting changes counts distinct values.
+	readings := make([]int64, timeNowUniqueCalls)
+	for i := range readings {
+		readings[i] = time.Now().UnixNano()
+	}
+	res.UniqueTimestamps = 1
+	for i := 1; i < len(readings); i++ {
+		if readings[i] != readings[i-1] {
+			res.UniqueTimestamps++
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+func benchTimerAccuracy(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		TimerAccuracyResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	targetMs, err := queryInt(r, "target_ms", 1, 1, 1000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 1000, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	target := time.Duration(targetMs) * time.Millisecond
+	if target*time.Duration(count) > maxTimerAccuracyDuration {
+		http.Error(w, fmt.Sprintf("query parameters target_ms and count: the sleeps must add up to at most %s", maxTimerAccuracyDuration), 400)
+		return
+	}
+
+	response.TimerAccuracyResult = *benchmarkTimerAccuracy(target, count)
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type TimerAccuracyResult struct {
+	TargetMs  int64
+	Count     int
+	Overshoot OvershootStats
+	Warning   string `json:",omitempty"`
+}
+
+// OvershootStats is how much longer than asked the sleeps took, in
+// microseconds.
+type OvershootStats struct {
+	MinUs float64
+	P50Us float64
+	P95Us float64
+	P99Us float64
+}
+
+const maxTimerAccuracyDuration = time.Minute
+
+// timerNoisyOvershoot is the p99 overshoot above which timers are too
+// imprecise for rate limiting and retry backoff.
+const timerNoisyOvershoot = 10 * time.Millisecond
+
+// benchmarkTimerAccuracy calls time.Sleep(target) count times and records how
+// long each call actually took beyond target.
+func benchmarkTimerAccuracy(target time.Duration, count int) *TimerAccuracyResult {
+	overshoots := make([]time.Duration, count)
+
+	for i := range overshoots {
+		start := time.Now()
+		time.Sleep(target)
+		overshoots[i] = time.Since(start) - target
+	}
+
+	slices.Sort(overshoots)
+
+	res := &TimerAccuracyResult{
+		TargetMs: target.Milliseconds(),
+		Count:    count,
+		Overshoot: OvershootStats{
+			MinUs: durationUs(overshoots[0]),
+			P50Us: durationUs(percentile(overshoots, 50)),
+			P95Us: durationUs(percentile(overshoots, 95)),
+			P99Us: durationUs(percentile(overshoots, 99)),
+		},
+	}
+
+	if p99 := percentile(overshoots, 99); p99 > timerNoisyOvershoot {
+		res.Warning = fmt.Sprintf("p99 sleep overshoot is %s, which points to a noisy scheduler and imprecise rate limiting", p99)
+	}
+
+	return res
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func benchTruncate(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		DiskMeta
+		TruncateResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+	response.DiskMeta = diskMeta()
+
+	dir, err := queryDir(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	sizeMB, err := queryInt(r, "size_mb", 64, 1, 64*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	count, err := queryInt(r, "count", 100, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkTruncate(dir, int64(sizeMB)*1024*1024, count)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.TruncateResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type TruncateResult struct {
+	FileSize int64
+	Count    int
+	Truncate LatencyStats
+	ReExpand LatencyStats
+}
+
+// benchmarkTruncate writes a file of fileSize bytes, then count times
+// truncates it to zero and extends it back to fileSize with os.File.Truncate,
+// timing the two steps separately.
+func benchmarkTruncate(dir string, fileSize int64, count int) (*TruncateResult, error) {
+	f, err := createTemp(dir, "truncate_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer removeTemp(f.Name())
+	defer f.Close()
+
+	buf := make([]byte, 32*1024)
+	for written := int64(0); written < fileSize; {
+		if err := fillContent(buf); err != nil {
+			return nil, err
+		}
+		n, err := f.Write(buf[:min(int64(len(buf)), fileSize-written)])
+		if err != nil {
+			return nil, fmt.Errorf("write to temp file: %w", err)
+		}
+		written += int64(n)
+	}
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("sync temp file: %w", err)
+	}
+
+	truncates := make([]time.Duration, 0, count)
+	expands := make([]time.Duration, 0, count)
+
+	for range count {
+		start := time.Now()
+		if err := f.Truncate(0); err != nil {
+			return nil, fmt.Errorf("truncate temp file: %w", err)
+		}
+		truncates = append(truncates, time.Since(start))
+
+		start = time.Now()
+		if err := f.Truncate(fileSize); err != nil {
+			return nil, fmt.Errorf("re-expand temp file: %w", err)
+		}
+		expands = append(expands, time.Since(start))
+	}
+
+	return &TruncateResult{
+		FileSize: fileSize,
+		Count:    count,
+		Truncate: latencyStats(truncates),
+		ReExpand: latencyStats(expands),
+	}, nil
+}
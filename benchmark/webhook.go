@@ -0,0 +1,45 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+var webhookSecret string
+
+// signWebhookPayload returns the hex-encoded HMAC-SHA256 of payload keyed
+// with secret. This is the signature webhook receivers are expected to check.
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// debugVerifySignature recomputes the signature of a payload with
+// BM_WEBHOOK_SECRET so receivers can check their implementation against
+// ours. It is only registered when BM_DEBUG=true.
+func debugVerifySignature(w http.ResponseWriter, r *http.Request) {
+	type Request struct {
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}
+
+	type Response struct {
+		Valid bool `json:"valid"`
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "decode request body: "+err.Error(), 400)
+		return
+	}
+
+	got, err := hex.DecodeString(req.Signature)
+	want, _ := hex.DecodeString(signWebhookPayload(webhookSecret, []byte(req.Payload)))
+	valid := err == nil && hmac.Equal(got, want)
+
+	writePrettyJSON(w, 200, Response{valid}, queryPretty(r))
+}
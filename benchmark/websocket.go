@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+func benchWebSocket(w http.ResponseWriter, r *http.Request) {
+	type Response struct {
+		RequestID string
+		WebSocketResult
+	}
+
+	var response Response
+
+	response.RequestID = requestIDFromContext(r.Context())
+
+	count, err := queryInt(r, "count", 100, 1, 100000)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	msgKB, err := queryInt(r, "msg_kb", 1, 1, 16*1024)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	res, err := benchmarkWebSocket(count, msgKB*1024)
+	if err != nil {
+		fmt.Println(response.RequestID, err)
+		writeJSONError(w, 500, err, "")
+		return
+	}
+
+	response.WebSocketResult = *res
+
+	writePrettyJSON(w, 200, response, queryPretty(r))
+}
+
+type WebSocketResult struct {
+	Connections     int
+	MessagesPerConn int
+	MessageSize     int
+	Setup           LatencyStats
+	RoundTrip       LatencyStats
+
+	// SetupToRoundTrip is the median handshake time divided by the median
+	// message round trip: how many messages a new connection costs.
+	SetupToRoundTrip float64
+}
+
+// webSocketConnections is how many connections benchmarkWebSocket opens, one
+// after the other, so the handshake is sampled more than once.
+const webSocketConnections = 10
+
+// benchmarkWebSocket starts an echo server on the loopback interface, opens
+// webSocketConnections connections to it and sends count messages of
+// messageSize bytes over each, timing the handshakes and the round trips
+// separately.
+func benchmarkWebSocket(count int, messageSize int) (*WebSocketResult, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := websocket.Accept(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.CloseNow()
+			c.SetReadLimit(int64(messageSize))
+
+			for {
+				typ, msg, err := c.Read(r.Context())
+				if err != nil {
+					return
+				}
+				if err := c.Write(r.Context(), typ, msg); err != nil {
+					return
+				}
+			}
+		}),
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	ctx := context.Background()
+	url := "ws://" + ln.Addr().String() + "/"
+
+	msg := make([]byte, messageSize)
+	if err := fillContent(msg); err != nil {
+		return nil, err
+	}
+
+	var setups, roundTrips []time.Duration
+
+	for range webSocketConnections {
+		start := time.Now()
+
+		c, _, err := websocket.Dial(ctx, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("dial websocket: %w", err)
+		}
+		c.SetReadLimit(int64(messageSize))
+
+		setups = append(setups, time.Since(start))
+
+		for range count {
+			sent := time.Now()
+
+			if err := c.Write(ctx, websocket.MessageBinary, msg); err != nil {
+				c.CloseNow()
+				return nil, fmt.Errorf("write message: %w", err)
+			}
+			_, echo, err := c.Read(ctx)
+			if err != nil {
+				c.CloseNow()
+				return nil, fmt.Errorf("read message: %w", err)
+			}
+			if !bytes.Equal(echo, msg) {
+				c.CloseNow()
+				return nil, fmt.Errorf("read message: echo differs from what was sent")
+			}
+
+			roundTrips = append(roundTrips, time.Since(sent))
+		}
+
+		c.Close(websocket.StatusNormalClosure, "")
+	}
+
+	res := &WebSocketResult{
+		Connections:     webSocketConnections,
+		MessagesPerConn: count,
+		MessageSize:     messageSize,
+		Setup:           latencyStats(setups),
+		RoundTrip:       latencyStats(roundTrips),
+	}
+	if res.RoundTrip.P50Ms > 0 {
+		res.SetupToRoundTrip = res.Setup.P50Ms / res.RoundTrip.P50Ms
+	}
+
+	return res, nil
+}